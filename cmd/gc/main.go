@@ -0,0 +1,54 @@
+// Command gc menghapus file upload yang tidak lagi direferensikan oleh
+// baris ProductPhoto, ProductVariant, ReviewPhoto maupun Toko.
+//
+//	go run ./cmd/gc -dry-run
+//	go run ./cmd/gc -min-age 24h
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	stored_file_repository "test-rakamin/internal/repository/stored_file"
+	upload_service "test-rakamin/internal/service/upload"
+	"test-rakamin/pkg/internalsql"
+	"test-rakamin/pkg/storage"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "hanya tampilkan file yang akan dihapus")
+	minAge := flag.Duration("min-age", time.Hour, "jangan hapus file yang diubah dalam rentang waktu ini")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Fatalf("Error loading .env file: %v", err)
+	}
+
+	db, err := internalsql.Connect(internalsql.DataSourceNameFromEnv())
+	if err != nil {
+		log.Fatalf("Gagal terhubung ke database: %v", err)
+	}
+
+	fileStorage, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Gagal menyiapkan storage: %v", err)
+	}
+
+	uploadService := upload_service.NewUploadService(stored_file_repository.NewStoredFileRepository(db), fileStorage)
+
+	removed, err := uploadService.CollectGarbage(*minAge, *dryRun)
+	for _, key := range removed {
+		if *dryRun {
+			log.Printf("akan dihapus: %s", key)
+		} else {
+			log.Printf("dihapus: %s", key)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Garbage collection gagal: %v", err)
+	}
+	log.Printf("Selesai, %d file tidak terpakai", len(removed))
+}
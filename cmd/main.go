@@ -1,31 +1,84 @@
 package main
 
 import (
-	"fmt"
 	"log"
-	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
 
+	cart_handler "test-rakamin/internal/handler/cart"
 	category_handler "test-rakamin/internal/handler/category"
+	category_attribute_handler "test-rakamin/internal/handler/category_attribute"
+	discount_handler "test-rakamin/internal/handler/discount"
+	file_handler "test-rakamin/internal/handler/file"
+	notification_handler "test-rakamin/internal/handler/notification"
+	payment_handler "test-rakamin/internal/handler/payment"
 	product_handler "test-rakamin/internal/handler/product"
+	product_import_handler "test-rakamin/internal/handler/product_import"
+	product_price_tier_handler "test-rakamin/internal/handler/product_price_tier"
+	product_variant_handler "test-rakamin/internal/handler/product_variant"
+	review_handler "test-rakamin/internal/handler/review"
+	seller_order_handler "test-rakamin/internal/handler/seller_order"
+	shipment_handler "test-rakamin/internal/handler/shipment"
+	shipping_handler "test-rakamin/internal/handler/shipping"
+	stock_alert_handler "test-rakamin/internal/handler/stock_alert"
+	stock_movement_handler "test-rakamin/internal/handler/stock_movement"
 	toko_handler "test-rakamin/internal/handler/toko"
 	trx_handler "test-rakamin/internal/handler/trx"
 	user_handler "test-rakamin/internal/handler/user"
+	voucher_handler "test-rakamin/internal/handler/voucher"
+	wishlist_handler "test-rakamin/internal/handler/wishlist"
 	"test-rakamin/internal/models"
+	alamat_repository "test-rakamin/internal/repository/alamat"
+	cart_repository "test-rakamin/internal/repository/cart"
 	category_repository "test-rakamin/internal/repository/category"
+	category_attribute_repository "test-rakamin/internal/repository/category_attribute"
+	discount_repository "test-rakamin/internal/repository/discount"
+	import_job_repository "test-rakamin/internal/repository/import_job"
+	notification_repository "test-rakamin/internal/repository/notification"
+	payment_repository "test-rakamin/internal/repository/payment"
 	product_repository "test-rakamin/internal/repository/product"
 	product_photo_repository "test-rakamin/internal/repository/product_photo"
+	product_price_tier_repository "test-rakamin/internal/repository/product_price_tier"
+	product_variant_repository "test-rakamin/internal/repository/product_variant"
+	review_repository "test-rakamin/internal/repository/review"
+	shipment_repository "test-rakamin/internal/repository/shipment"
+	stock_movement_repository "test-rakamin/internal/repository/stock_movement"
+	stock_reservation_repository "test-rakamin/internal/repository/stock_reservation"
+	stored_file_repository "test-rakamin/internal/repository/stored_file"
 	toko_repository "test-rakamin/internal/repository/toko"
 	trx_repository "test-rakamin/internal/repository/trx"
 	user_repository "test-rakamin/internal/repository/user"
+	voucher_repository "test-rakamin/internal/repository/voucher"
+	wishlist_repository "test-rakamin/internal/repository/wishlist"
+	cart_service "test-rakamin/internal/service/cart"
 	category_service "test-rakamin/internal/service/category"
+	category_attribute_service "test-rakamin/internal/service/category_attribute"
+	discount_service "test-rakamin/internal/service/discount"
+	notification_service "test-rakamin/internal/service/notification"
+	payment_service "test-rakamin/internal/service/payment"
 	product_service "test-rakamin/internal/service/product"
+	product_import_service "test-rakamin/internal/service/product_import"
+	product_price_tier_service "test-rakamin/internal/service/product_price_tier"
+	product_variant_service "test-rakamin/internal/service/product_variant"
+	review_service "test-rakamin/internal/service/review"
+	seller_order_service "test-rakamin/internal/service/seller_order"
+	shipment_service "test-rakamin/internal/service/shipment"
+	shipping_service "test-rakamin/internal/service/shipping"
+	stock_alert_service "test-rakamin/internal/service/stock_alert"
+	stock_movement_service "test-rakamin/internal/service/stock_movement"
 	toko_service "test-rakamin/internal/service/toko"
 	trx_service "test-rakamin/internal/service/trx"
+	upload_service "test-rakamin/internal/service/upload"
 	user_service "test-rakamin/internal/service/user"
+	voucher_service "test-rakamin/internal/service/voucher"
+	wishlist_service "test-rakamin/internal/service/wishlist"
 	"test-rakamin/pkg/internalsql"
+	"test-rakamin/pkg/payment"
+	"test-rakamin/pkg/shipping"
+	"test-rakamin/pkg/storage"
+	"test-rakamin/pkg/worker"
 )
 
 func main() {
@@ -35,15 +88,7 @@ func main() {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
 
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-
-	dataSourceName := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", dbUser, dbPassword, dbHost, dbPort, dbName)
-
-	db, err := internalsql.Connect(dataSourceName)
+	db, err := internalsql.Connect(internalsql.DataSourceNameFromEnv())
 	if err != nil {
 		log.Fatalf("Gagal terhubung ke database: %v", err)
 	}
@@ -53,42 +98,168 @@ func main() {
 		&models.Alamat{},
 		&models.Toko{},
 		&models.Category{},
+		&models.CategoryAttribute{},
 		&models.Product{},
 		&models.ProductPhoto{},
+		&models.ProductVariant{},
+		&models.ProductPriceTier{},
+		&models.Discount{},
+		&models.ProductAttribute{},
+		&models.StoredFile{},
 		&models.ProductLog{},
+		&models.AuditLog{},
+		&models.StockMovement{},
+		&models.Voucher{},
 		&models.Trx{},
+		&models.TrxToko{},
+		&models.VoucherRedemption{},
 		&models.DetailTrx{},
+		&models.StockReservation{},
+		&models.Shipment{},
+		&models.ShipmentEvent{},
+		&models.Payment{},
+		&models.PaymentCallback{},
+		&models.Notification{},
+		&models.ImportJob{},
+		&models.Review{},
+		&models.ReviewPhoto{},
+		&models.Wishlist{},
+		&models.CartItem{},
 	)
 	if err != nil {
 		log.Fatalf("Gagal migrasi database: %v", err)
 	}
 
+	fileStorage, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Gagal menyiapkan storage: %v", err)
+	}
+
+	couriers, err := shipping.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Gagal menyiapkan kurir: %v", err)
+	}
+
+	paymentProviders, err := payment.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Gagal menyiapkan payment provider: %v", err)
+	}
+
 	app := fiber.New()
 
 	userRepo := user_repository.NewUserRepository(db)
 	categoryRepo := category_repository.NewCategoryRepository(db)
+	categoryAttributeRepo := category_attribute_repository.NewCategoryAttributeRepository(db)
 	tokoRepo := toko_repository.NewTokoRepository(db)
 	productRepo := product_repository.NewProductRepository(db)
 	productPhotoRepo := product_photo_repository.NewProductPhotoRepository(db)
+	productVariantRepo := product_variant_repository.NewProductVariantRepository(db)
+	productPriceTierRepo := product_price_tier_repository.NewProductPriceTierRepository(db)
 	trxRepo := trx_repository.NewTrxRepository(db)
+	storedFileRepo := stored_file_repository.NewStoredFileRepository(db)
+	stockMovementRepo := stock_movement_repository.NewStockMovementRepository(db)
+	stockReservationRepo := stock_reservation_repository.NewStockReservationRepository(db)
+	notificationRepo := notification_repository.NewNotificationRepository(db)
+	importJobRepo := import_job_repository.NewImportJobRepository(db)
+	reviewRepo := review_repository.NewReviewRepository(db)
+	wishlistRepo := wishlist_repository.NewWishlistRepository(db)
+	discountRepo := discount_repository.NewDiscountRepository(db)
+	voucherRepo := voucher_repository.NewVoucherRepository(db)
+	cartRepo := cart_repository.NewCartRepository(db)
+	alamatRepo := alamat_repository.NewAlamatRepository(db)
+	shipmentRepo := shipment_repository.NewShipmentRepository(db)
+	paymentRepo := payment_repository.NewPaymentRepository(db)
 
+	uploadService := upload_service.NewUploadService(storedFileRepo, fileStorage)
+	notificationService := notification_service.NewNotificationService(notificationRepo)
+	stockAlertService := stock_alert_service.NewStockAlertService(productRepo, tokoRepo, stockReservationRepo, notificationService)
 	userService := user_service.NewUserService(userRepo)
-	categoryService := category_service.NewCategoryService(categoryRepo)
-	tokoService := toko_service.NewTokoService(tokoRepo)
-	productService := product_service.NewProductService(productRepo, productPhotoRepo)
-	trxService := trx_service.NewTrxService(trxRepo, productRepo)
+	categoryService := category_service.NewCategoryService(categoryRepo, userRepo)
+	categoryAttributeService := category_attribute_service.NewCategoryAttributeService(categoryAttributeRepo, categoryRepo, userRepo, categoryService)
+	discountService := discount_service.NewDiscountService(discountRepo, productRepo, tokoRepo, userRepo, categoryService)
+	tokoService := toko_service.NewTokoService(tokoRepo, uploadService)
+	productService := product_service.NewProductService(productRepo, productPhotoRepo, stockMovementRepo, stockReservationRepo, wishlistRepo, tokoRepo, uploadService, stockAlertService, categoryService, categoryAttributeService, discountService)
+	productVariantService := product_variant_service.NewProductVariantService(productVariantRepo, productRepo, tokoRepo, stockMovementRepo, stockReservationRepo, uploadService, stockAlertService)
+	productPriceTierService := product_price_tier_service.NewProductPriceTierService(productPriceTierRepo, productRepo, tokoRepo)
+	stockMovementService := stock_movement_service.NewStockMovementService(stockMovementRepo, productRepo, tokoRepo)
+	productImportService := product_import_service.NewProductImportService(importJobRepo, productRepo, categoryRepo, tokoRepo, productService, uploadService)
+	reviewService := review_service.NewReviewService(reviewRepo, productRepo, tokoRepo, uploadService)
+	wishlistService := wishlist_service.NewWishlistService(wishlistRepo, productRepo, productService)
+	voucherService := voucher_service.NewVoucherService(voucherRepo, tokoRepo, userRepo)
+	shippingService := shipping_service.NewShippingService(couriers, alamatRepo, userRepo, productRepo)
+	paymentService := payment_service.NewPaymentService(paymentRepo, paymentProviders, tokoRepo, notificationService)
+	trxService := trx_service.NewTrxService(trxRepo, productRepo, userRepo, stockAlertService, discountService, voucherService, shippingService, paymentService, worker.DurationFromEnv("RESERVATION_TTL", 30*time.Minute))
+	cartService := cart_service.NewCartService(cartRepo, productRepo, productService, trxService)
+	sellerOrderService := seller_order_service.NewSellerOrderService(trxRepo, tokoRepo, notificationService, paymentService)
+	shipmentService := shipment_service.NewShipmentService(shipmentRepo, trxRepo, tokoRepo, notificationService)
 
 	userHandler := user_handler.NewUserHandler(userService)
 	categoryHandler := category_handler.NewCategoryHandler(categoryService)
+	categoryAttributeHandler := category_attribute_handler.NewCategoryAttributeHandler(categoryAttributeService)
 	tokoHandler := toko_handler.NewTokoHandler(tokoService)
 	productHandler := product_handler.NewProductHandler(productService)
+	productVariantHandler := product_variant_handler.NewProductVariantHandler(productVariantService)
+	productPriceTierHandler := product_price_tier_handler.NewProductPriceTierHandler(productPriceTierService)
+	stockMovementHandler := stock_movement_handler.NewStockMovementHandler(stockMovementService)
 	trxHandler := trx_handler.NewTrxHandler(trxService)
+	fileHandler := file_handler.NewFileHandler(fileStorage)
+	notificationHandler := notification_handler.NewNotificationHandler(notificationService)
+	stockAlertHandler := stock_alert_handler.NewStockAlertHandler(stockAlertService)
+	productImportHandler := product_import_handler.NewProductImportHandler(productImportService)
+	reviewHandler := review_handler.NewReviewHandler(reviewService)
+	wishlistHandler := wishlist_handler.NewWishlistHandler(wishlistService)
+	discountHandler := discount_handler.NewDiscountHandler(discountService)
+	voucherHandler := voucher_handler.NewVoucherHandler(voucherService)
+	cartHandler := cart_handler.NewCartHandler(cartService)
+	sellerOrderHandler := seller_order_handler.NewSellerOrderHandler(sellerOrderService)
+	shippingHandler := shipping_handler.NewShippingHandler(shippingService)
+	shipmentHandler := shipment_handler.NewShipmentHandler(shipmentService)
+	paymentHandler := payment_handler.NewPaymentHandler(paymentService)
 
+	// Sama seperti route produk di bawah, route dengan prefix /api/user,
+	// /api/category dan /api/toko didaftarkan sebelum handler pemilik prefix
+	// tersebut.
+	notificationHandler.RegisterRoutes(app)
+	wishlistHandler.RegisterRoutes(app)
 	userHandler.RegisterRoutes(app)
+	categoryAttributeHandler.RegisterRoutes(app)
 	categoryHandler.RegisterRoutes(app)
-	tokoHandler.RegisterRoutes(app)
+	stockAlertHandler.RegisterRoutes(app)
+	// Route /api/product/:id/... didaftarkan sebelum productHandler karena
+	// group auth productHandler memasang JWT middleware untuk seluruh prefix
+	// /api/product.
+	productVariantHandler.RegisterRoutes(app)
+	productPriceTierHandler.RegisterRoutes(app)
+	stockMovementHandler.RegisterRoutes(app)
+	productImportHandler.RegisterRoutes(app)
+	reviewHandler.RegisterRoutes(app)
 	productHandler.RegisterRoutes(app)
+	// productHandler juga mendaftarkan /api/toko/my/products, jadi
+	// tokoHandler didaftarkan sesudahnya.
+	sellerOrderHandler.RegisterRoutes(app)
+	// shipmentHandler juga mendaftarkan webhook publik di /api/shipping,
+	// jadi harus sebelum group JWT shippingHandler.
+	shipmentHandler.RegisterRoutes(app)
+	tokoHandler.RegisterRoutes(app)
 	trxHandler.RegisterRoutes(app)
+	discountHandler.RegisterRoutes(app)
+	voucherHandler.RegisterRoutes(app)
+	cartHandler.RegisterRoutes(app)
+	shippingHandler.RegisterRoutes(app)
+	paymentHandler.RegisterRoutes(app)
+	fileHandler.RegisterRoutes(app)
+
+	if err := productImportService.FailInterruptedJobs(); err != nil {
+		log.Printf("Gagal menandai import job yang terhenti: %v", err)
+	}
+
+	// Reaper membatalkan transaksi yang tidak dibayar dan melepas
+	// reservasi stoknya.
+	worker.Every("reservation reaper", worker.DurationFromEnv("RESERVATION_REAPER_INTERVAL", time.Minute), trxService.ReleaseExpiredReservations)
+	// Scheduler menerbitkan dan mengarsipkan produk sesuai jadwalnya.
+	worker.Every("product scheduler", worker.DurationFromEnv("PRODUCT_SCHEDULER_INTERVAL", time.Minute), productService.ApplySchedules)
+	// Refund yang gagal dikirim ke provider dicoba ulang sampai berhasil.
+	worker.Every("refund retry", worker.DurationFromEnv("REFUND_RETRY_INTERVAL", 5*time.Minute), paymentService.RetryRefunds)
 
 	log.Println("Server berjalan di http://localhost:3000")
 	log.Fatal(app.Listen(":3000"))
@@ -0,0 +1,154 @@
+package cart_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	cart_service "test-rakamin/internal/service/cart"
+	payment_service "test-rakamin/internal/service/payment"
+	shipping_service "test-rakamin/internal/service/shipping"
+	voucher_service "test-rakamin/internal/service/voucher"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetCart(c *fiber.Ctx) error
+	AddItem(c *fiber.Ctx) error
+	UpdateItem(c *fiber.Ctx) error
+	RemoveItem(c *fiber.Ctx) error
+	ClearCart(c *fiber.Ctx) error
+	Checkout(c *fiber.Ctx) error
+}
+
+type cartHandlerImpl struct {
+	cartService cart_service.CartService
+}
+
+func NewCartHandler(service cart_service.CartService) CartHandler {
+	return &cartHandlerImpl{cartService: service}
+}
+
+func (h *cartHandlerImpl) RegisterRoutes(app *fiber.App) {
+	cartRoutes := app.Group("/api/cart", middleware.JWTMiddleware())
+	cartRoutes.Get("/", h.GetCart)
+	cartRoutes.Delete("/", h.ClearCart)
+	cartRoutes.Post("/items", h.AddItem)
+	cartRoutes.Put("/items/:id", h.UpdateItem)
+	cartRoutes.Delete("/items/:id", h.RemoveItem)
+	cartRoutes.Post("/checkout", h.Checkout)
+}
+
+func (h *cartHandlerImpl) GetCart(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	cart, err := h.cartService.GetCart(userID)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get cart", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", cart)
+}
+
+func (h *cartHandlerImpl) AddItem(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	var payload models.CartItemPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid cart payload", err.Error())
+	}
+
+	cart, err := h.cartService.AddItem(userID, &payload)
+	if err != nil {
+		return cartError(c, "Failed to add cart item", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", cart)
+}
+
+func (h *cartHandlerImpl) UpdateItem(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid cart item ID", err.Error())
+	}
+
+	var payload models.CartItemPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid cart payload", err.Error())
+	}
+
+	cart, err := h.cartService.UpdateItem(userID, uint(id), payload.Kuantitas)
+	if err != nil {
+		return cartError(c, "Failed to update cart item", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", cart)
+}
+
+func (h *cartHandlerImpl) RemoveItem(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid cart item ID", err.Error())
+	}
+
+	if err := h.cartService.RemoveItem(userID, uint(id)); err != nil {
+		return cartError(c, "Failed to remove cart item", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
+
+func (h *cartHandlerImpl) ClearCart(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	if err := h.cartService.ClearCart(userID); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to clear cart", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
+
+func (h *cartHandlerImpl) Checkout(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	var payload models.CartCheckoutPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid checkout payload", err.Error())
+	}
+
+	trx, err := h.cartService.Checkout(userID, &payload)
+	if err != nil {
+		return cartError(c, "Failed to checkout cart", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", trx)
+}
+
+func cartError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, cart_service.ErrInvalidCartItem), errors.Is(err, cart_service.ErrEmptyCart), errors.Is(err, voucher_service.ErrVoucherNotApplicable),
+		errors.Is(err, shipping_service.ErrInvalidShipping), errors.Is(err, shipping_service.ErrNotServed), errors.Is(err, payment_service.ErrInvalidPayment):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, cart_service.ErrItemNotFound):
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -1,6 +1,7 @@
 package category_handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -15,6 +16,7 @@ import (
 type CategoryHandler interface {
 	RegisterRoutes(app *fiber.App)
 	GetAllCategories(c *fiber.Ctx) error
+	GetCategoryTree(c *fiber.Ctx) error
 	GetCategoryByID(c *fiber.Ctx) error
 	CreateCategory(c *fiber.Ctx) error
 	UpdateCategory(c *fiber.Ctx) error
@@ -32,6 +34,7 @@ func NewCategoryHandler(service category_service.CategoryService) CategoryHandle
 func (h *categoryHandlerImpl) RegisterRoutes(app *fiber.App) {
 	categoryRoutes := app.Group("/api/category")
 	categoryRoutes.Get("/", h.GetAllCategories)
+	categoryRoutes.Get("/tree", h.GetCategoryTree)
 	categoryRoutes.Get("/:id", h.GetCategoryByID)
 
 	authCategoryRoutes := app.Group("/api/category", middleware.JWTMiddleware())
@@ -48,6 +51,14 @@ func (h *categoryHandlerImpl) GetAllCategories(c *fiber.Ctx) error {
 	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", categories)
 }
 
+func (h *categoryHandlerImpl) GetCategoryTree(c *fiber.Ctx) error {
+	tree, err := h.categoryService.GetCategoryTree()
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get category tree", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", tree)
+}
+
 func (h *categoryHandlerImpl) GetCategoryByID(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
@@ -61,11 +72,21 @@ func (h *categoryHandlerImpl) GetCategoryByID(c *fiber.Ctx) error {
 }
 
 func (h *categoryHandlerImpl) CreateCategory(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
 	var category models.Category
 	if err := c.BodyParser(&category); err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid request body", err.Error())
 	}
-	newCategory, err := h.categoryService.CreateCategory(&category)
+	newCategory, err := h.categoryService.CreateCategory(userID, &category)
+	if errors.Is(err, category_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to create category", err.Error())
+	}
+	if errors.Is(err, category_service.ErrInvalidParent) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to create category", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to create category", err.Error())
 	}
@@ -77,11 +98,21 @@ func (h *categoryHandlerImpl) UpdateCategory(c *fiber.Ctx) error {
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid category ID", err.Error())
 	}
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
 	var category models.Category
 	if err := c.BodyParser(&category); err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid request body", err.Error())
 	}
-	updatedCategory, err := h.categoryService.UpdateCategory(uint(id), &category)
+	updatedCategory, err := h.categoryService.UpdateCategory(userID, uint(id), &category)
+	if errors.Is(err, category_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to update category", err.Error())
+	}
+	if errors.Is(err, category_service.ErrInvalidParent) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to update category", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to update category", err.Error())
 	}
@@ -93,7 +124,33 @@ func (h *categoryHandlerImpl) DeleteCategory(c *fiber.Ctx) error {
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid category ID", err.Error())
 	}
-	err = h.categoryService.DeleteCategory(uint(id))
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	// move_to opsional: kategori tujuan untuk produk dan subkategori milik
+	// kategori yang dihapus.
+	var moveTo *uint
+	if raw := c.Query("move_to"); raw != "" {
+		target, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid move_to category ID", err.Error())
+		}
+		targetID := uint(target)
+		moveTo = &targetID
+	}
+
+	err = h.categoryService.DeleteCategory(userID, uint(id), moveTo)
+	if errors.Is(err, category_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to delete category", err.Error())
+	}
+	if errors.Is(err, category_service.ErrCategoryInUse) {
+		return utils.ErrorResponseFiber(c, http.StatusConflict, "Failed to delete category", err.Error())
+	}
+	if errors.Is(err, category_service.ErrInvalidTarget) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to delete category", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to delete category", err.Error())
 	}
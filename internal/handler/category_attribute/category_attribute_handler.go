@@ -0,0 +1,135 @@
+package category_attribute_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	category_attribute_service "test-rakamin/internal/service/category_attribute"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CategoryAttributeHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetSchema(c *fiber.Ctx) error
+	CreateAttribute(c *fiber.Ctx) error
+	UpdateAttribute(c *fiber.Ctx) error
+	DeleteAttribute(c *fiber.Ctx) error
+}
+
+type categoryAttributeHandlerImpl struct {
+	attributeService category_attribute_service.CategoryAttributeService
+}
+
+func NewCategoryAttributeHandler(service category_attribute_service.CategoryAttributeService) CategoryAttributeHandler {
+	return &categoryAttributeHandlerImpl{attributeService: service}
+}
+
+func (h *categoryAttributeHandlerImpl) RegisterRoutes(app *fiber.App) {
+	attributeRoutes := app.Group("/api/category/:id/attributes")
+	attributeRoutes.Get("/", h.GetSchema)
+
+	authAttributeRoutes := app.Group("/api/category/:id/attributes", middleware.JWTMiddleware())
+	authAttributeRoutes.Post("/", h.CreateAttribute)
+	authAttributeRoutes.Put("/:attribute_id", h.UpdateAttribute)
+	authAttributeRoutes.Delete("/:attribute_id", h.DeleteAttribute)
+}
+
+func (h *categoryAttributeHandlerImpl) GetSchema(c *fiber.Ctx) error {
+	categoryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid category ID", err.Error())
+	}
+	schema, err := h.attributeService.GetSchema(uint(categoryID))
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get category attributes", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", schema)
+}
+
+func (h *categoryAttributeHandlerImpl) CreateAttribute(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	categoryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid category ID", err.Error())
+	}
+
+	var payload models.CategoryAttributePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid attribute payload", err.Error())
+	}
+
+	attribute, err := h.attributeService.CreateAttribute(userID, uint(categoryID), &payload)
+	if err != nil {
+		return attributeError(c, "Failed to create attribute", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", attribute)
+}
+
+func (h *categoryAttributeHandlerImpl) UpdateAttribute(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	categoryID, attributeID, err := parseIDs(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ID", err.Error())
+	}
+
+	var payload models.CategoryAttributePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid attribute payload", err.Error())
+	}
+
+	attribute, err := h.attributeService.UpdateAttribute(userID, categoryID, attributeID, &payload)
+	if err != nil {
+		return attributeError(c, "Failed to update attribute", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", attribute)
+}
+
+func (h *categoryAttributeHandlerImpl) DeleteAttribute(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	categoryID, attributeID, err := parseIDs(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ID", err.Error())
+	}
+
+	if err := h.attributeService.DeleteAttribute(userID, categoryID, attributeID); err != nil {
+		return attributeError(c, "Failed to delete attribute", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
+
+func parseIDs(c *fiber.Ctx) (uint, uint, error) {
+	categoryID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	attributeID, err := strconv.ParseUint(c.Params("attribute_id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(categoryID), uint(attributeID), nil
+}
+
+func attributeError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, category_attribute_service.ErrForbidden):
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, message, err.Error())
+	case errors.Is(err, category_attribute_service.ErrInvalidAttribute):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, message, err.Error())
+	}
+}
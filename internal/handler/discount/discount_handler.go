@@ -0,0 +1,107 @@
+package discount_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	discount_service "test-rakamin/internal/service/discount"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DiscountHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetActiveDiscounts(c *fiber.Ctx) error
+	GetMyDiscounts(c *fiber.Ctx) error
+	CreateDiscount(c *fiber.Ctx) error
+	DeleteDiscount(c *fiber.Ctx) error
+}
+
+type discountHandlerImpl struct {
+	discountService discount_service.DiscountService
+}
+
+func NewDiscountHandler(service discount_service.DiscountService) DiscountHandler {
+	return &discountHandlerImpl{discountService: service}
+}
+
+func (h *discountHandlerImpl) RegisterRoutes(app *fiber.App) {
+	discountRoutes := app.Group("/api/discounts")
+	discountRoutes.Get("/", h.GetActiveDiscounts)
+
+	authDiscountRoutes := app.Group("/api/discounts", middleware.JWTMiddleware())
+	authDiscountRoutes.Get("/my", h.GetMyDiscounts)
+	authDiscountRoutes.Post("/", h.CreateDiscount)
+	authDiscountRoutes.Delete("/:id", h.DeleteDiscount)
+}
+
+func (h *discountHandlerImpl) GetActiveDiscounts(c *fiber.Ctx) error {
+	discounts, err := h.discountService.GetActiveDiscounts()
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get discounts", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", discounts)
+}
+
+func (h *discountHandlerImpl) GetMyDiscounts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	discounts, err := h.discountService.GetMyDiscounts(userID)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get discounts", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", discounts)
+}
+
+func (h *discountHandlerImpl) CreateDiscount(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	var payload models.DiscountPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid discount payload", err.Error())
+	}
+
+	discount, err := h.discountService.CreateDiscount(userID, &payload)
+	if err != nil {
+		return discountError(c, "Failed to create discount", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", discount)
+}
+
+func (h *discountHandlerImpl) DeleteDiscount(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid discount ID", err.Error())
+	}
+
+	if err := h.discountService.DeleteDiscount(userID, uint(id)); err != nil {
+		return discountError(c, "Failed to delete discount", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
+
+func discountError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, discount_service.ErrForbidden):
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, message, err.Error())
+	case errors.Is(err, discount_service.ErrInvalidDiscount):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, discount_service.ErrNotFound):
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,83 @@
+package file_handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"test-rakamin/pkg/signedurl"
+	"test-rakamin/pkg/storage"
+	"test-rakamin/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+type FileHandler interface {
+	RegisterRoutes(app *fiber.App)
+	ServeFile(c *fiber.Ctx) error
+}
+
+type fileHandlerImpl struct {
+	storage storage.Storage
+}
+
+func NewFileHandler(store storage.Storage) FileHandler {
+	return &fileHandlerImpl{storage: store}
+}
+
+func (h *fileHandlerImpl) RegisterRoutes(app *fiber.App) {
+	app.Get("/files/*", h.ServeFile)
+	app.Head("/files/*", h.ServeFile)
+}
+
+func (h *fileHandlerImpl) ServeFile(c *fiber.Ctx) error {
+	key := c.Params("*")
+
+	// Nama file upload selalu unik, jadi file publik aman di-cache selamanya.
+	cacheControl := "public, max-age=31536000, immutable"
+	if signedurl.IsPrivate(key) {
+		expiresAt, err := signedurl.Verify(key, c.Query("expires"), c.Query("signature"), time.Now())
+		if err != nil {
+			return utils.ErrorResponseFiber(c, http.StatusForbidden, "Access denied", err.Error())
+		}
+		cacheControl = fmt.Sprintf("private, max-age=%d", int(time.Until(expiresAt).Seconds()))
+	}
+
+	file, err := h.storage.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "File not found", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to get file", err.Error())
+	}
+	defer file.Close()
+
+	// http.ServeContent butuh io.ReadSeeker untuk melayani Range request;
+	// driver yang hanya memberi stream (S3) dibaca dulu ke memori.
+	var content io.ReadSeeker
+	var modTime time.Time
+	if f, ok := file.(*os.File); ok {
+		content = f
+		if info, err := f.Stat(); err == nil {
+			modTime = info.ModTime()
+		}
+	} else {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to read file", err.Error())
+		}
+		content = bytes.NewReader(data)
+	}
+
+	return adaptor.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", `"`+key+`"`)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, key, modTime, content)
+	})(c)
+}
@@ -0,0 +1,75 @@
+package notification_handler
+
+import (
+	"net/http"
+	"strconv"
+
+	notification_service "test-rakamin/internal/service/notification"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetNotifications(c *fiber.Ctx) error
+	MarkRead(c *fiber.Ctx) error
+	MarkAllRead(c *fiber.Ctx) error
+}
+
+type notificationHandlerImpl struct {
+	notificationService notification_service.NotificationService
+}
+
+func NewNotificationHandler(service notification_service.NotificationService) NotificationHandler {
+	return &notificationHandlerImpl{notificationService: service}
+}
+
+func (h *notificationHandlerImpl) RegisterRoutes(app *fiber.App) {
+	notificationRoutes := app.Group("/api/user/notifications", middleware.JWTMiddleware())
+	notificationRoutes.Get("/", h.GetNotifications)
+	notificationRoutes.Put("/read-all", h.MarkAllRead)
+	notificationRoutes.Put("/:id/read", h.MarkRead)
+}
+
+func (h *notificationHandlerImpl) GetNotifications(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	notifications, err := h.notificationService.GetNotifications(userID, c.QueryBool("unread"))
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get notifications", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", notifications)
+}
+
+func (h *notificationHandlerImpl) MarkRead(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid notification ID", err.Error())
+	}
+
+	if err := h.notificationService.MarkRead(userID, uint(id)); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to update notification", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", nil)
+}
+
+func (h *notificationHandlerImpl) MarkAllRead(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to update notifications", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", nil)
+}
@@ -0,0 +1,53 @@
+package payment_handler
+
+import (
+	"errors"
+	"net/http"
+
+	payment_service "test-rakamin/internal/service/payment"
+	"test-rakamin/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetMethods(c *fiber.Ctx) error
+	Webhook(c *fiber.Ctx) error
+}
+
+type paymentHandlerImpl struct {
+	paymentService payment_service.PaymentService
+}
+
+func NewPaymentHandler(service payment_service.PaymentService) PaymentHandler {
+	return &paymentHandlerImpl{paymentService: service}
+}
+
+// RegisterRoutes tidak memakai JWT. Keaslian webhook diperiksa lewat
+// signature provider.
+func (h *paymentHandlerImpl) RegisterRoutes(app *fiber.App) {
+	paymentRoutes := app.Group("/api/payments")
+	paymentRoutes.Get("/methods", h.GetMethods)
+	paymentRoutes.Post("/webhook/:provider", h.Webhook)
+}
+
+func (h *paymentHandlerImpl) GetMethods(c *fiber.Ctx) error {
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", h.paymentService.Methods())
+}
+
+func (h *paymentHandlerImpl) Webhook(c *fiber.Ctx) error {
+	err := h.paymentService.HandleWebhook(c.Params("provider"), c.Body(), c.Get("X-Webhook-Signature"))
+	switch {
+	case err == nil:
+		return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to POST data", nil)
+	case errors.Is(err, payment_service.ErrInvalidSignature):
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Failed to process payment webhook", err.Error())
+	case errors.Is(err, payment_service.ErrInvalidCallback):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to process payment webhook", err.Error())
+	case errors.Is(err, payment_service.ErrProviderNotFound), errors.Is(err, payment_service.ErrPaymentNotFound):
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to process payment webhook", err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to process payment webhook", err.Error())
+	}
+}
@@ -1,10 +1,16 @@
 package product_handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	category_attribute_service "test-rakamin/internal/service/category_attribute"
 	product_service "test-rakamin/internal/service/product"
 	"test-rakamin/utils"
 	"test-rakamin/utils/middleware"
@@ -16,6 +22,7 @@ type ProductHandler interface {
 	RegisterRoutes(app *fiber.App)
 	GetAllProducts(c *fiber.Ctx) error
 	GetProductByID(c *fiber.Ctx) error
+	GetMyProducts(c *fiber.Ctx) error
 	CreateProduct(c *fiber.Ctx) error
 	UpdateProduct(c *fiber.Ctx) error
 	DeleteProduct(c *fiber.Ctx) error
@@ -32,41 +39,101 @@ func NewProductHandler(service product_service.ProductService) ProductHandler {
 func (h *productHandlerImpl) RegisterRoutes(app *fiber.App) {
 	productRoutes := app.Group("/api/product")
 	productRoutes.Get("/", h.GetAllProducts)
-	productRoutes.Get("/:id", h.GetProductByID)
+	productRoutes.Get("/:id", middleware.OptionalJWTMiddleware(), h.GetProductByID)
 
 	authProductRoutes := app.Group("/api/product", middleware.JWTMiddleware())
 	authProductRoutes.Post("/", h.CreateProduct)
 	authProductRoutes.Put("/:id", h.UpdateProduct)
 	authProductRoutes.Delete("/:id", h.DeleteProduct)
+
+	app.Get("/api/toko/my/products", middleware.JWTMiddleware(), h.GetMyProducts)
 }
 
 func (h *productHandlerImpl) GetAllProducts(c *fiber.Ctx) error {
-	products, err := h.productService.GetAllProducts(
-		c.Query("nama_produk"),
-		c.Query("category_id"),
-		c.Query("toko_id"),
-		c.Query("min_harga"),
-		c.Query("max_harga"),
-	)
+	products, err := h.productService.GetAllProducts(product_repository.ProductFilter{
+		Nama:       c.Query("nama_produk"),
+		CategoryID: c.Query("category_id"),
+		TokoID:     c.Query("toko_id"),
+		Status:     models.ProductPublished,
+		MinHarga:   c.Query("min_harga"),
+		MaxHarga:   c.Query("max_harga"),
+		MinRating:  c.Query("min_rating"),
+		Atribut:    attributeFilter(c),
+		Sort:       c.Query("sort"),
+	})
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get products", err.Error())
 	}
 	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", products)
 }
 
+// attributeFilter membaca filter atribut dari query attr[kode]=nilai.
+func attributeFilter(c *fiber.Ctx) map[string]string {
+	filter := map[string]string{}
+	for key, value := range c.Queries() {
+		if strings.HasPrefix(key, "attr[") && strings.HasSuffix(key, "]") {
+			filter[strings.TrimSuffix(strings.TrimPrefix(key, "attr["), "]")] = value
+		}
+	}
+	return filter
+}
+
 func (h *productHandlerImpl) GetProductByID(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
 	}
-	product, err := h.productService.GetProductByID(uint(id))
+	// user_id hanya ada bila request membawa token yang valid.
+	userID, _ := c.Locals("user_id").(uint)
+	product, err := h.productService.GetProductByID(uint(id), userID)
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get product", err.Error())
 	}
 	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", product)
 }
 
+func (h *productHandlerImpl) GetMyProducts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	products, err := h.productService.GetMyProducts(userID, c.Query("status"))
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get products", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", products)
+}
+
+// formSchedule membaca jadwal publish_at dan unpublish_at (RFC3339) dari
+// form. Field yang kosong dibiarkan nil.
+func formSchedule(c *fiber.Ctx, product *models.Product) error {
+	var err error
+	if product.PublishAt, err = formTime(c, "publish_at"); err != nil {
+		return err
+	}
+	product.UnpublishAt, err = formTime(c, "unpublish_at")
+	return err
+}
+
+func formTime(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.FormValue(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 time", key)
+	}
+	return &t, nil
+}
+
 func (h *productHandlerImpl) CreateProduct(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
 	form, err := c.MultipartForm()
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid form data", err.Error())
@@ -77,9 +144,22 @@ func (h *productHandlerImpl) CreateProduct(c *fiber.Ctx) error {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product payload", err.Error())
 	}
 
+	if err := formSchedule(c, &productPayload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product payload", err.Error())
+	}
+
 	photos := form.File["photos"]
 
-	newProduct, err := h.productService.CreateProduct(&productPayload, photos)
+	newProduct, err := h.productService.CreateProduct(userID, &productPayload, c.FormValue("atribut"), photos)
+	if errors.Is(err, utils.ErrInvalidUpload) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product photo", err.Error())
+	}
+	if errors.Is(err, product_service.ErrInvalidStatus) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product status", err.Error())
+	}
+	if errors.Is(err, category_attribute_service.ErrInvalidAttributes) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product attributes", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to create product", err.Error())
 	}
@@ -88,6 +168,11 @@ func (h *productHandlerImpl) CreateProduct(c *fiber.Ctx) error {
 }
 
 func (h *productHandlerImpl) UpdateProduct(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
@@ -103,9 +188,22 @@ func (h *productHandlerImpl) UpdateProduct(c *fiber.Ctx) error {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product payload", err.Error())
 	}
 
+	if err := formSchedule(c, &productPayload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product payload", err.Error())
+	}
+
 	photos := form.File["photos"]
 
-	updatedProduct, err := h.productService.UpdateProduct(uint(id), &productPayload, photos)
+	updatedProduct, err := h.productService.UpdateProduct(userID, uint(id), &productPayload, c.FormValue("atribut"), photos)
+	if errors.Is(err, product_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to update product", err.Error())
+	}
+	if errors.Is(err, product_service.ErrInvalidStatus) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product status", err.Error())
+	}
+	if errors.Is(err, category_attribute_service.ErrInvalidAttributes) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product attributes", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to update product", err.Error())
 	}
@@ -118,7 +216,14 @@ func (h *productHandlerImpl) DeleteProduct(c *fiber.Ctx) error {
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
 	}
-	err = h.productService.DeleteProduct(uint(id))
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	err = h.productService.DeleteProduct(userID, uint(id))
+	if errors.Is(err, product_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to delete product", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to delete product", err.Error())
 	}
@@ -0,0 +1,74 @@
+package product_handler
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"test-rakamin/internal/models"
+	product_service "test-rakamin/internal/service/product"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeProductService menganggap semua produk milik user 1.
+type fakeProductService struct {
+	product_service.ProductService
+}
+
+func (fakeProductService) UpdateProduct(userID, id uint, updatedProduct *models.Product, atribut string, photos []*multipart.FileHeader) (*models.Product, error) {
+	if userID != 1 {
+		return nil, product_service.ErrForbidden
+	}
+	return updatedProduct, nil
+}
+
+func (fakeProductService) DeleteProduct(userID, id uint) error {
+	if userID != 1 {
+		return product_service.ErrForbidden
+	}
+	return nil
+}
+
+func TestProductOwnershipStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		userID uint
+		want   int
+	}{
+		{"pemilik mengubah produk", http.MethodPut, 1, http.StatusOK},
+		{"seller lain mengubah produk", http.MethodPut, 2, http.StatusForbidden},
+		{"pemilik menghapus produk", http.MethodDelete, 1, http.StatusOK},
+		{"seller lain menghapus produk", http.MethodDelete, 2, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewProductHandler(fakeProductService{})
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("user_id", tt.userID)
+				return c.Next()
+			})
+			app.Put("/api/product/:id", h.UpdateProduct)
+			app.Delete("/api/product/:id", h.DeleteProduct)
+
+			var body bytes.Buffer
+			w := multipart.NewWriter(&body)
+			w.WriteField("NamaProduct", "Kaos")
+			w.Close()
+			req := httptest.NewRequest(tt.method, "/api/product/10", &body)
+			req.Header.Set("Content-Type", w.FormDataContentType())
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package product_import_handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	product_import_service "test-rakamin/internal/service/product_import"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductImportHandler interface {
+	RegisterRoutes(app *fiber.App)
+	ImportProducts(c *fiber.Ctx) error
+	GetImportJob(c *fiber.Ctx) error
+	ExportProducts(c *fiber.Ctx) error
+}
+
+type productImportHandlerImpl struct {
+	productImportService product_import_service.ProductImportService
+}
+
+func NewProductImportHandler(service product_import_service.ProductImportService) ProductImportHandler {
+	return &productImportHandlerImpl{productImportService: service}
+}
+
+// Route ini harus didaftarkan sebelum route /api/product/:id milik
+// productHandler, karena itu JWT dipasang per route.
+func (h *productImportHandlerImpl) RegisterRoutes(app *fiber.App) {
+	app.Post("/api/product/import", middleware.JWTMiddleware(), h.ImportProducts)
+	app.Get("/api/product/import/:job_id", middleware.JWTMiddleware(), h.GetImportJob)
+	app.Get("/api/product/export", middleware.JWTMiddleware(), h.ExportProducts)
+}
+
+func (h *productImportHandlerImpl) ImportProducts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to parse form file", err.Error())
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to open form file", err.Error())
+	}
+	defer file.Close()
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+	job, err := h.productImportService.StartImport(userID, fileHeader.Filename, file, dryRun)
+	if errors.Is(err, product_import_service.ErrInvalidCSV) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid import file", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to start import", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusAccepted, "Import started", job)
+}
+
+func (h *productImportHandlerImpl) GetImportJob(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("job_id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid import job ID", err.Error())
+	}
+
+	job, err := h.productImportService.GetImportJob(userID, uint(id))
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get import job", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", job)
+}
+
+func (h *productImportHandlerImpl) ExportProducts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	var buf bytes.Buffer
+	if err := h.productImportService.ExportProducts(userID, c.BaseURL(), &buf); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to export products", err.Error())
+	}
+
+	c.Attachment("products.csv")
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,99 @@
+package product_price_tier_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	product_price_tier_service "test-rakamin/internal/service/product_price_tier"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductPriceTierHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetPriceTiers(c *fiber.Ctx) error
+	CreatePriceTier(c *fiber.Ctx) error
+	DeletePriceTier(c *fiber.Ctx) error
+}
+
+type productPriceTierHandlerImpl struct {
+	priceTierService product_price_tier_service.ProductPriceTierService
+}
+
+func NewProductPriceTierHandler(service product_price_tier_service.ProductPriceTierService) ProductPriceTierHandler {
+	return &productPriceTierHandlerImpl{priceTierService: service}
+}
+
+func (h *productPriceTierHandlerImpl) RegisterRoutes(app *fiber.App) {
+	priceTierRoutes := app.Group("/api/product/:id/price-tiers")
+	priceTierRoutes.Get("/", h.GetPriceTiers)
+
+	authPriceTierRoutes := app.Group("/api/product/:id/price-tiers", middleware.JWTMiddleware())
+	authPriceTierRoutes.Post("/", h.CreatePriceTier)
+	authPriceTierRoutes.Delete("/:tier_id", h.DeletePriceTier)
+}
+
+func (h *productPriceTierHandlerImpl) GetPriceTiers(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+	tiers, err := h.priceTierService.GetPriceTiers(uint(productID))
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get price tiers", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", tiers)
+}
+
+func (h *productPriceTierHandlerImpl) CreatePriceTier(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+
+	var payload models.ProductPriceTierPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	tier, err := h.priceTierService.CreatePriceTier(userID, uint(productID), &payload)
+	if errors.Is(err, product_price_tier_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to create price tier", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to create price tier", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", tier)
+}
+
+func (h *productPriceTierHandlerImpl) DeletePriceTier(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+	tierID, err := strconv.ParseUint(c.Params("tier_id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid price tier ID", err.Error())
+	}
+
+	err = h.priceTierService.DeletePriceTier(userID, uint(productID), uint(tierID))
+	if errors.Is(err, product_price_tier_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to delete price tier", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to delete price tier", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
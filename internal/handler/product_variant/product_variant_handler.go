@@ -0,0 +1,179 @@
+package product_variant_handler
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	product_variant_service "test-rakamin/internal/service/product_variant"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductVariantHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetVariants(c *fiber.Ctx) error
+	GetVariantByID(c *fiber.Ctx) error
+	CreateVariant(c *fiber.Ctx) error
+	UpdateVariant(c *fiber.Ctx) error
+	DeleteVariant(c *fiber.Ctx) error
+}
+
+type productVariantHandlerImpl struct {
+	variantService product_variant_service.ProductVariantService
+}
+
+func NewProductVariantHandler(service product_variant_service.ProductVariantService) ProductVariantHandler {
+	return &productVariantHandlerImpl{variantService: service}
+}
+
+func (h *productVariantHandlerImpl) RegisterRoutes(app *fiber.App) {
+	variantRoutes := app.Group("/api/product/:id/variants")
+	variantRoutes.Get("/", h.GetVariants)
+	variantRoutes.Get("/:variant_id", h.GetVariantByID)
+
+	authVariantRoutes := app.Group("/api/product/:id/variants", middleware.JWTMiddleware())
+	authVariantRoutes.Post("/", h.CreateVariant)
+	authVariantRoutes.Put("/:variant_id", h.UpdateVariant)
+	authVariantRoutes.Delete("/:variant_id", h.DeleteVariant)
+}
+
+func (h *productVariantHandlerImpl) GetVariants(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+	variants, err := h.variantService.GetVariants(uint(productID))
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get variants", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", variants)
+}
+
+func (h *productVariantHandlerImpl) GetVariantByID(c *fiber.Ctx) error {
+	productID, variantID, err := parseIDs(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ID", err.Error())
+	}
+	variant, err := h.variantService.GetVariantByID(productID, variantID)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get variant", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", variant)
+}
+
+func (h *productVariantHandlerImpl) CreateVariant(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+
+	var payload models.ProductVariantPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid variant payload", err.Error())
+	}
+	photo, err := formPhoto(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to parse form file", err.Error())
+	}
+
+	variant, err := h.variantService.CreateVariant(userID, uint(productID), &payload, photo)
+	if err != nil {
+		return variantError(c, "Failed to create variant", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", variant)
+}
+
+func (h *productVariantHandlerImpl) UpdateVariant(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, variantID, err := parseIDs(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ID", err.Error())
+	}
+
+	var payload models.ProductVariantPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid variant payload", err.Error())
+	}
+	photo, err := formPhoto(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to parse form file", err.Error())
+	}
+
+	variant, err := h.variantService.UpdateVariant(userID, productID, variantID, &payload, photo)
+	if err != nil {
+		return variantError(c, "Failed to update variant", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", variant)
+}
+
+func (h *productVariantHandlerImpl) DeleteVariant(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, variantID, err := parseIDs(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ID", err.Error())
+	}
+
+	if err := h.variantService.DeleteVariant(userID, productID, variantID); err != nil {
+		if errors.Is(err, product_variant_service.ErrForbidden) {
+			return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to delete variant", err.Error())
+		}
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to delete variant", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
+
+func parseIDs(c *fiber.Ctx) (uint, uint, error) {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	variantID, err := strconv.ParseUint(c.Params("variant_id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(productID), uint(variantID), nil
+}
+
+// formPhoto mengambil file "photo" yang opsional. Request JSON tidak
+// membawa foto sama sekali.
+func formPhoto(c *fiber.Ctx) (*multipart.FileHeader, error) {
+	if c.Is("json") {
+		return nil, nil
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		log.Printf("Failed to get form file: %v", err)
+		return nil, err
+	}
+	if files := form.File["photo"]; len(files) > 0 {
+		return files[0], nil
+	}
+	return nil, nil
+}
+
+func variantError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, product_variant_service.ErrForbidden):
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, message, err.Error())
+	case errors.Is(err, utils.ErrInvalidUpload):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,163 @@
+package review_handler
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"test-rakamin/internal/models"
+	review_service "test-rakamin/internal/service/review"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetReviews(c *fiber.Ctx) error
+	CreateReview(c *fiber.Ctx) error
+	UpdateReview(c *fiber.Ctx) error
+	ReplyReview(c *fiber.Ctx) error
+}
+
+type reviewHandlerImpl struct {
+	reviewService review_service.ReviewService
+}
+
+func NewReviewHandler(service review_service.ReviewService) ReviewHandler {
+	return &reviewHandlerImpl{reviewService: service}
+}
+
+func (h *reviewHandlerImpl) RegisterRoutes(app *fiber.App) {
+	reviewRoutes := app.Group("/api/product/:id/reviews")
+	reviewRoutes.Get("/", h.GetReviews)
+
+	authReviewRoutes := app.Group("/api/product/:id/reviews", middleware.JWTMiddleware())
+	authReviewRoutes.Post("/", h.CreateReview)
+	authReviewRoutes.Put("/:review_id", h.UpdateReview)
+	authReviewRoutes.Put("/:review_id/reply", h.ReplyReview)
+}
+
+func (h *reviewHandlerImpl) GetReviews(c *fiber.Ctx) error {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+	reviews, err := h.reviewService.GetReviews(uint(productID), c.QueryInt("rating"))
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get reviews", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", reviews)
+}
+
+func (h *reviewHandlerImpl) CreateReview(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+
+	var payload models.ReviewPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid review payload", err.Error())
+	}
+	photos, err := formPhotos(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to parse form file", err.Error())
+	}
+
+	review, err := h.reviewService.CreateReview(userID, uint(productID), &payload, photos)
+	if err != nil {
+		return reviewError(c, "Failed to create review", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", review)
+}
+
+func (h *reviewHandlerImpl) UpdateReview(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, reviewID, err := parseIDs(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ID", err.Error())
+	}
+
+	var payload models.ReviewPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid review payload", err.Error())
+	}
+	photos, err := formPhotos(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to parse form file", err.Error())
+	}
+
+	review, err := h.reviewService.UpdateReview(userID, productID, reviewID, &payload, photos)
+	if err != nil {
+		return reviewError(c, "Failed to update review", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", review)
+}
+
+func (h *reviewHandlerImpl) ReplyReview(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, reviewID, err := parseIDs(c)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ID", err.Error())
+	}
+
+	var payload models.ReviewReplyPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid reply payload", err.Error())
+	}
+
+	review, err := h.reviewService.ReplyReview(userID, productID, reviewID, payload.Balasan)
+	if err != nil {
+		return reviewError(c, "Failed to reply review", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", review)
+}
+
+func parseIDs(c *fiber.Ctx) (uint, uint, error) {
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	reviewID, err := strconv.ParseUint(c.Params("review_id"), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint(productID), uint(reviewID), nil
+}
+
+// formPhotos mengambil file "photos" yang opsional dari request multipart.
+func formPhotos(c *fiber.Ctx) ([]*multipart.FileHeader, error) {
+	if !strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		return nil, nil
+	}
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+	return form.File["photos"], nil
+}
+
+func reviewError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, review_service.ErrForbidden), errors.Is(err, review_service.ErrNotPurchased):
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, message, err.Error())
+	case errors.Is(err, review_service.ErrInvalidReview), errors.Is(err, utils.ErrInvalidUpload):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,180 @@
+package seller_order_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	seller_order_service "test-rakamin/internal/service/seller_order"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SellerOrderHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetOrders(c *fiber.Ctx) error
+	GetOrder(c *fiber.Ctx) error
+	AcceptOrder(c *fiber.Ctx) error
+	ShipOrder(c *fiber.Ctx) error
+	RejectOrder(c *fiber.Ctx) error
+	CollectCOD(c *fiber.Ctx) error
+	GetSettlement(c *fiber.Ctx) error
+}
+
+type sellerOrderHandlerImpl struct {
+	sellerOrderService seller_order_service.SellerOrderService
+}
+
+func NewSellerOrderHandler(service seller_order_service.SellerOrderService) SellerOrderHandler {
+	return &sellerOrderHandlerImpl{sellerOrderService: service}
+}
+
+func (h *sellerOrderHandlerImpl) RegisterRoutes(app *fiber.App) {
+	orderRoutes := app.Group("/api/toko/my/orders", middleware.JWTMiddleware())
+	orderRoutes.Get("/", h.GetOrders)
+	orderRoutes.Get("/:id", h.GetOrder)
+	orderRoutes.Put("/:id/accept", h.AcceptOrder)
+	orderRoutes.Put("/:id/ship", h.ShipOrder)
+	orderRoutes.Put("/:id/reject", h.RejectOrder)
+	orderRoutes.Put("/:id/cod-collected", h.CollectCOD)
+
+	settlementRoutes := app.Group("/api/toko/my/settlement", middleware.JWTMiddleware())
+	settlementRoutes.Get("/", h.GetSettlement)
+}
+
+func (h *sellerOrderHandlerImpl) GetOrders(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	orders, err := h.sellerOrderService.GetOrders(userID, c.Query("status"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		return orderError(c, "Failed to get orders", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", orders)
+}
+
+func (h *sellerOrderHandlerImpl) GetOrder(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+	}
+
+	order, err := h.sellerOrderService.GetOrder(userID, uint(id))
+	if err != nil {
+		return orderError(c, "Failed to get order", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", order)
+}
+
+func (h *sellerOrderHandlerImpl) AcceptOrder(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+	}
+
+	order, err := h.sellerOrderService.AcceptOrder(userID, uint(id))
+	if err != nil {
+		return orderError(c, "Failed to accept order", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", order)
+}
+
+func (h *sellerOrderHandlerImpl) ShipOrder(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+	}
+
+	var payload models.ShipOrderPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid ship payload", err.Error())
+	}
+
+	order, err := h.sellerOrderService.ShipOrder(userID, uint(id), payload.NoResi)
+	if err != nil {
+		return orderError(c, "Failed to ship order", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", order)
+}
+
+func (h *sellerOrderHandlerImpl) RejectOrder(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+	}
+
+	// Alasan penolakan opsional, jadi body boleh kosong.
+	var payload models.RejectOrderPayload
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid reject payload", err.Error())
+		}
+	}
+
+	order, err := h.sellerOrderService.RejectOrder(userID, uint(id), payload.Alasan)
+	if err != nil {
+		return orderError(c, "Failed to reject order", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", order)
+}
+
+func (h *sellerOrderHandlerImpl) CollectCOD(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+	}
+	order, err := h.sellerOrderService.CollectCOD(userID, uint(id))
+	if err != nil {
+		return orderError(c, "Failed to collect cod", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", order)
+}
+
+func (h *sellerOrderHandlerImpl) GetSettlement(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	report, err := h.sellerOrderService.GetSettlement(userID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		return orderError(c, "Failed to get settlement", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", report)
+}
+
+func orderError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, seller_order_service.ErrInvalidOrder):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, seller_order_service.ErrTokoNotFound), errors.Is(err, seller_order_service.ErrOrderNotFound):
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, message, err.Error())
+	case errors.Is(err, seller_order_service.ErrInvalidStatus):
+		return utils.ErrorResponseFiber(c, http.StatusConflict, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
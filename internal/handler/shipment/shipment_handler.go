@@ -0,0 +1,82 @@
+package shipment_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	shipment_service "test-rakamin/internal/service/shipment"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShipmentHandler interface {
+	RegisterRoutes(app *fiber.App)
+	AddEvent(c *fiber.Ctx) error
+	CourierWebhook(c *fiber.Ctx) error
+}
+
+type shipmentHandlerImpl struct {
+	shipmentService shipment_service.ShipmentService
+}
+
+func NewShipmentHandler(service shipment_service.ShipmentService) ShipmentHandler {
+	return &shipmentHandlerImpl{shipmentService: service}
+}
+
+func (h *shipmentHandlerImpl) RegisterRoutes(app *fiber.App) {
+	// Webhook kurir tidak memakai JWT, keasliannya diperiksa lewat
+	// signature.
+	app.Post("/api/shipping/webhook/:kurir", h.CourierWebhook)
+
+	shipmentRoutes := app.Group("/api/toko/my/orders", middleware.JWTMiddleware())
+	shipmentRoutes.Post("/:id/shipment/events", h.AddEvent)
+}
+
+func (h *shipmentHandlerImpl) AddEvent(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid order ID", err.Error())
+	}
+
+	var payload models.ShipmentEventPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid shipment event payload", err.Error())
+	}
+
+	shipment, err := h.shipmentService.AddEvent(userID, uint(id), &payload)
+	if err != nil {
+		return shipmentError(c, "Failed to add shipment event", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", shipment)
+}
+
+func (h *shipmentHandlerImpl) CourierWebhook(c *fiber.Ctx) error {
+	err := h.shipmentService.HandleCourierWebhook(c.Params("kurir"), c.Body(), c.Get("X-Webhook-Signature"))
+	if err != nil {
+		return shipmentError(c, "Failed to process courier webhook", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to POST data", nil)
+}
+
+func shipmentError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, shipment_service.ErrInvalidEvent):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, shipment_service.ErrInvalidSignature):
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, message, err.Error())
+	case errors.Is(err, shipment_service.ErrOrderNotFound), errors.Is(err, shipment_service.ErrShipmentNotFound):
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, message, err.Error())
+	case errors.Is(err, shipment_service.ErrDelivered):
+		return utils.ErrorResponseFiber(c, http.StatusConflict, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
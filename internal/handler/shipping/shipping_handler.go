@@ -0,0 +1,52 @@
+package shipping_handler
+
+import (
+	"errors"
+	"net/http"
+
+	"test-rakamin/internal/models"
+	shipping_service "test-rakamin/internal/service/shipping"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShippingHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetQuotes(c *fiber.Ctx) error
+}
+
+type shippingHandlerImpl struct {
+	shippingService shipping_service.ShippingService
+}
+
+func NewShippingHandler(service shipping_service.ShippingService) ShippingHandler {
+	return &shippingHandlerImpl{shippingService: service}
+}
+
+func (h *shippingHandlerImpl) RegisterRoutes(app *fiber.App) {
+	shippingRoutes := app.Group("/api/shipping", middleware.JWTMiddleware())
+	shippingRoutes.Post("/quote", h.GetQuotes)
+}
+
+func (h *shippingHandlerImpl) GetQuotes(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	var payload models.ShippingQuotePayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid shipping payload", err.Error())
+	}
+
+	options, err := h.shippingService.GetQuotes(userID, &payload)
+	if errors.Is(err, shipping_service.ErrInvalidShipping) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to get shipping quotes", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get shipping quotes", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to POST data", options)
+}
@@ -0,0 +1,41 @@
+package stock_alert_handler
+
+import (
+	"net/http"
+
+	stock_alert_service "test-rakamin/internal/service/stock_alert"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockAlertHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetLowStockProducts(c *fiber.Ctx) error
+}
+
+type stockAlertHandlerImpl struct {
+	stockAlertService stock_alert_service.StockAlertService
+}
+
+func NewStockAlertHandler(service stock_alert_service.StockAlertService) StockAlertHandler {
+	return &stockAlertHandlerImpl{stockAlertService: service}
+}
+
+func (h *stockAlertHandlerImpl) RegisterRoutes(app *fiber.App) {
+	app.Get("/api/toko/my/low-stock", middleware.JWTMiddleware(), h.GetLowStockProducts)
+}
+
+func (h *stockAlertHandlerImpl) GetLowStockProducts(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	products, err := h.stockAlertService.GetLowStockProducts(userID)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get low stock products", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", products)
+}
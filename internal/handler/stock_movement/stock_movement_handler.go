@@ -0,0 +1,73 @@
+package stock_movement_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	stock_movement_service "test-rakamin/internal/service/stock_movement"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockMovementHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetStockMovements(c *fiber.Ctx) error
+	ReconcileStock(c *fiber.Ctx) error
+}
+
+type stockMovementHandlerImpl struct {
+	stockMovementService stock_movement_service.StockMovementService
+}
+
+func NewStockMovementHandler(service stock_movement_service.StockMovementService) StockMovementHandler {
+	return &stockMovementHandlerImpl{stockMovementService: service}
+}
+
+func (h *stockMovementHandlerImpl) RegisterRoutes(app *fiber.App) {
+	stockRoutes := app.Group("/api/product/:id/stock-movements", middleware.JWTMiddleware())
+	stockRoutes.Get("/", h.GetStockMovements)
+	stockRoutes.Post("/reconcile", h.ReconcileStock)
+}
+
+func (h *stockMovementHandlerImpl) GetStockMovements(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+
+	movements, err := h.stockMovementService.GetStockMovements(userID, uint(productID))
+	if errors.Is(err, stock_movement_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to get stock movements", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get stock movements", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", movements)
+}
+
+func (h *stockMovementHandlerImpl) ReconcileStock(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+
+	result, err := h.stockMovementService.ReconcileStock(userID, uint(productID))
+	if errors.Is(err, stock_movement_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to reconcile stock", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to reconcile stock", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to POST data", result)
+}
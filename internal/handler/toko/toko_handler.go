@@ -1,9 +1,13 @@
 package toko_handler
 
 import (
+	"errors"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"strconv"
+	"strings"
+	"test-rakamin/internal/models"
 	toko_service "test-rakamin/internal/service/toko"
 	"test-rakamin/utils"
 	"test-rakamin/utils/middleware"
@@ -17,6 +21,9 @@ type TokoHandler interface {
 	GetAllToko(c *fiber.Ctx) error
 	GetTokoByID(c *fiber.Ctx) error
 	UpdateToko(c *fiber.Ctx) error
+	UpdateCOD(c *fiber.Ctx) error
+	GetWebhookSecret(c *fiber.Ctx) error
+	RotateWebhookSecret(c *fiber.Ctx) error
 }
 
 type tokoHandlerImpl struct {
@@ -36,6 +43,9 @@ func (h *tokoHandlerImpl) RegisterRoutes(app *fiber.App) {
 	authTokoRoutes := app.Group("/api/toko", middleware.JWTMiddleware())
 	authTokoRoutes.Get("/my", h.GetMyToko)
 	authTokoRoutes.Put("/:id_toko", h.UpdateToko)
+	authTokoRoutes.Put("/:id_toko/cod", h.UpdateCOD)
+	authTokoRoutes.Get("/:id_toko/webhook-secret", h.GetWebhookSecret)
+	authTokoRoutes.Put("/:id_toko/webhook-secret", h.RotateWebhookSecret)
 }
 
 func (h *tokoHandlerImpl) GetMyToko(c *fiber.Ctx) error {
@@ -72,22 +82,120 @@ func (h *tokoHandlerImpl) GetTokoByID(c *fiber.Ctx) error {
 }
 
 func (h *tokoHandlerImpl) UpdateToko(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
 	id, err := strconv.ParseUint(c.Params("id_toko"), 10, 32)
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid toko ID", err.Error())
 	}
 
 	namaToko := c.FormValue("nama_toko")
-	file, err := c.FormFile("photo")
-	if err != nil && err != http.ErrMissingFile {
-		log.Printf("Failed to get form file: %v", err)
-		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to parse form file", err.Error())
+	webhookURL := c.FormValue("webhook_url")
+	var idKota uint
+	if raw := c.FormValue("id_kota"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid id_kota", err.Error())
+		}
+		idKota = uint(parsed)
+	}
+	// Foto opsional; fiber tidak mengembalikan http.ErrMissingFile bila
+	// field photo tidak dikirim, jadi dicek langsung dari form.
+	var file *multipart.FileHeader
+	if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+		form, err := c.MultipartForm()
+		if err != nil {
+			log.Printf("Failed to get form file: %v", err)
+			return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to parse form file", err.Error())
+		}
+		if files := form.File["photo"]; len(files) > 0 {
+			file = files[0]
+		}
 	}
 
-	updatedToko, err := h.tokoService.UpdateToko(uint(id), namaToko, file)
+	updatedToko, err := h.tokoService.UpdateToko(userID, uint(id), namaToko, webhookURL, idKota, file)
+	if errors.Is(err, utils.ErrInvalidUpload) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid toko photo", err.Error())
+	}
+	if errors.Is(err, toko_service.ErrInvalidWebhookURL) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid webhook url", err.Error())
+	}
+	if errors.Is(err, toko_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to update toko", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to update toko", err.Error())
 	}
 
 	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", updatedToko)
 }
+
+func (h *tokoHandlerImpl) UpdateCOD(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id_toko"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid toko ID", err.Error())
+	}
+
+	var payload models.TokoCODPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid cod payload", err.Error())
+	}
+
+	updatedToko, err := h.tokoService.UpdateCOD(userID, uint(id), &payload)
+	if errors.Is(err, toko_service.ErrInvalidCOD) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid cod payload", err.Error())
+	}
+	if errors.Is(err, toko_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to update toko", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to update toko", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", updatedToko)
+}
+
+func (h *tokoHandlerImpl) GetWebhookSecret(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id_toko"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid toko ID", err.Error())
+	}
+
+	secret, err := h.tokoService.GetWebhookSecret(userID, uint(id))
+	if errors.Is(err, toko_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to get webhook secret", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to get webhook secret", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", secret)
+}
+
+func (h *tokoHandlerImpl) RotateWebhookSecret(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id_toko"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid toko ID", err.Error())
+	}
+
+	secret, err := h.tokoService.RotateWebhookSecret(userID, uint(id))
+	if errors.Is(err, toko_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to rotate webhook secret", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to rotate webhook secret", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", secret)
+}
@@ -1,11 +1,15 @@
 package trx_handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"test-rakamin/internal/models"
+	payment_service "test-rakamin/internal/service/payment"
+	shipping_service "test-rakamin/internal/service/shipping"
 	trx_service "test-rakamin/internal/service/trx"
+	voucher_service "test-rakamin/internal/service/voucher"
 	"test-rakamin/utils"
 	"test-rakamin/utils/middleware"
 
@@ -16,6 +20,7 @@ type TrxHandler interface {
 	RegisterRoutes(app *fiber.App)
 	GetAllTrx(c *fiber.Ctx) error
 	GetTrxByID(c *fiber.Ctx) error
+	PreviewTrx(c *fiber.Ctx) error
 	CreateTrx(c *fiber.Ctx) error
 }
 
@@ -31,6 +36,7 @@ func (h *trxHandlerImpl) RegisterRoutes(app *fiber.App) {
 	trxRoutes := app.Group("/api/trx", middleware.JWTMiddleware())
 	trxRoutes.Get("/", h.GetAllTrx)
 	trxRoutes.Get("/:id", h.GetTrxByID)
+	trxRoutes.Post("/preview", h.PreviewTrx)
 	trxRoutes.Post("/", h.CreateTrx)
 }
 
@@ -62,6 +68,29 @@ func (h *trxHandlerImpl) GetTrxByID(c *fiber.Ctx) error {
 	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", trx)
 }
 
+// PreviewTrx menerima payload yang sama dengan CreateTrx dan mengembalikan
+// rincian harganya tanpa membuat transaksi.
+func (h *trxHandlerImpl) PreviewTrx(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found")
+	}
+
+	var payload models.TrxPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	trx, err := h.trxService.PreviewTrx(userID, &payload)
+	if errors.Is(err, voucher_service.ErrVoucherNotApplicable) || errors.Is(err, shipping_service.ErrInvalidShipping) || errors.Is(err, shipping_service.ErrNotServed) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to preview transaction", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to preview transaction", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to POST data", trx)
+}
+
 func (h *trxHandlerImpl) CreateTrx(c *fiber.Ctx) error {
 	userID, ok := c.Locals("user_id").(uint)
 	if !ok {
@@ -74,6 +103,9 @@ func (h *trxHandlerImpl) CreateTrx(c *fiber.Ctx) error {
 	}
 
 	newTrx, err := h.trxService.CreateTrx(userID, &payload)
+	if errors.Is(err, voucher_service.ErrVoucherNotApplicable) || errors.Is(err, shipping_service.ErrInvalidShipping) || errors.Is(err, shipping_service.ErrNotServed) || errors.Is(err, payment_service.ErrInvalidPayment) {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to create transaction", err.Error())
+	}
 	if err != nil {
 		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to create transaction", err.Error())
 	}
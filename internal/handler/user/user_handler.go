@@ -1,7 +1,9 @@
 package user_handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"test-rakamin/internal/models"
 	user_service "test-rakamin/internal/service/user"
@@ -16,6 +18,10 @@ type UserHandler interface {
 	Login(c *fiber.Ctx) error
 	GetMyProfile(c *fiber.Ctx) error
 	UpdateProfile(c *fiber.Ctx) error
+	ApplyReseller(c *fiber.Ctx) error
+	GetResellerApplications(c *fiber.Ctx) error
+	ApproveReseller(c *fiber.Ctx) error
+	RejectReseller(c *fiber.Ctx) error
 	RegisterRoutes(app *fiber.App)
 }
 
@@ -36,6 +42,12 @@ func (h *userHandlerImpl) RegisterRoutes(app *fiber.App) {
 	userRoutes := app.Group("/api/user", middleware.JWTMiddleware())
 	userRoutes.Get("/", h.GetMyProfile)
 	userRoutes.Put("/", h.UpdateProfile)
+	userRoutes.Post("/reseller", h.ApplyReseller)
+
+	adminRoutes := app.Group("/api/admin", middleware.JWTMiddleware())
+	adminRoutes.Get("/resellers", h.GetResellerApplications)
+	adminRoutes.Put("/resellers/:id/approve", h.ApproveReseller)
+	adminRoutes.Put("/resellers/:id/reject", h.RejectReseller)
 }
 
 func (h *userHandlerImpl) Register(c *fiber.Ctx) error {
@@ -106,3 +118,63 @@ func (h *userHandlerImpl) UpdateProfile(c *fiber.Ctx) error {
 
 	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", user)
 }
+
+func (h *userHandlerImpl) ApplyReseller(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	user, err := h.userService.ApplyReseller(userID)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to apply as reseller", err.Error())
+	}
+
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to POST data", user)
+}
+
+func (h *userHandlerImpl) GetResellerApplications(c *fiber.Ctx) error {
+	adminID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	users, err := h.userService.GetResellerApplications(adminID, c.Query("status"))
+	if errors.Is(err, user_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to get reseller applications", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get reseller applications", err.Error())
+	}
+
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", users)
+}
+
+func (h *userHandlerImpl) ApproveReseller(c *fiber.Ctx) error {
+	return h.reviewReseller(c, true)
+}
+
+func (h *userHandlerImpl) RejectReseller(c *fiber.Ctx) error {
+	return h.reviewReseller(c, false)
+}
+
+func (h *userHandlerImpl) reviewReseller(c *fiber.Ctx, approve bool) error {
+	adminID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid user ID", err.Error())
+	}
+
+	user, err := h.userService.ReviewResellerApplication(adminID, uint(id), approve)
+	if errors.Is(err, user_service.ErrForbidden) {
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, "Failed to review reseller application", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Failed to review reseller application", err.Error())
+	}
+
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to UPDATE data", user)
+}
@@ -0,0 +1,97 @@
+package voucher_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	voucher_service "test-rakamin/internal/service/voucher"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type VoucherHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetMyVouchers(c *fiber.Ctx) error
+	CreateVoucher(c *fiber.Ctx) error
+	DeleteVoucher(c *fiber.Ctx) error
+}
+
+type voucherHandlerImpl struct {
+	voucherService voucher_service.VoucherService
+}
+
+func NewVoucherHandler(service voucher_service.VoucherService) VoucherHandler {
+	return &voucherHandlerImpl{voucherService: service}
+}
+
+// Tidak ada daftar voucher publik; kode voucher dibagikan sendiri oleh
+// penerbitnya.
+func (h *voucherHandlerImpl) RegisterRoutes(app *fiber.App) {
+	voucherRoutes := app.Group("/api/vouchers", middleware.JWTMiddleware())
+	voucherRoutes.Get("/my", h.GetMyVouchers)
+	voucherRoutes.Post("/", h.CreateVoucher)
+	voucherRoutes.Delete("/:id", h.DeleteVoucher)
+}
+
+func (h *voucherHandlerImpl) GetMyVouchers(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	vouchers, err := h.voucherService.GetMyVouchers(userID)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get vouchers", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", vouchers)
+}
+
+func (h *voucherHandlerImpl) CreateVoucher(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	var payload models.VoucherPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid voucher payload", err.Error())
+	}
+
+	voucher, err := h.voucherService.CreateVoucher(userID, &payload)
+	if err != nil {
+		return voucherError(c, "Failed to create voucher", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", voucher)
+}
+
+func (h *voucherHandlerImpl) DeleteVoucher(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid voucher ID", err.Error())
+	}
+
+	if err := h.voucherService.DeleteVoucher(userID, uint(id)); err != nil {
+		return voucherError(c, "Failed to delete voucher", err)
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
+
+func voucherError(c *fiber.Ctx, message string, err error) error {
+	switch {
+	case errors.Is(err, voucher_service.ErrForbidden):
+		return utils.ErrorResponseFiber(c, http.StatusForbidden, message, err.Error())
+	case errors.Is(err, voucher_service.ErrInvalidVoucher):
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, message, err.Error())
+	case errors.Is(err, voucher_service.ErrNotFound):
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, message, err.Error())
+	default:
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, message, err.Error())
+	}
+}
@@ -0,0 +1,90 @@
+package wishlist_handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"test-rakamin/internal/models"
+	wishlist_service "test-rakamin/internal/service/wishlist"
+	"test-rakamin/utils"
+	"test-rakamin/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WishlistHandler interface {
+	RegisterRoutes(app *fiber.App)
+	GetWishlist(c *fiber.Ctx) error
+	AddToWishlist(c *fiber.Ctx) error
+	RemoveFromWishlist(c *fiber.Ctx) error
+}
+
+type wishlistHandlerImpl struct {
+	wishlistService wishlist_service.WishlistService
+}
+
+func NewWishlistHandler(service wishlist_service.WishlistService) WishlistHandler {
+	return &wishlistHandlerImpl{wishlistService: service}
+}
+
+func (h *wishlistHandlerImpl) RegisterRoutes(app *fiber.App) {
+	wishlistRoutes := app.Group("/api/user/wishlist", middleware.JWTMiddleware())
+	wishlistRoutes.Get("/", h.GetWishlist)
+	wishlistRoutes.Post("/", h.AddToWishlist)
+	wishlistRoutes.Delete("/:product_id", h.RemoveFromWishlist)
+}
+
+func (h *wishlistHandlerImpl) GetWishlist(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	wishlists, err := h.wishlistService.GetWishlist(userID)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to get wishlist", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to GET data", wishlists)
+}
+
+func (h *wishlistHandlerImpl) AddToWishlist(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+
+	var payload models.WishlistPayload
+	if err := c.BodyParser(&payload); err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid wishlist payload", err.Error())
+	}
+
+	err := h.wishlistService.AddToWishlist(userID, payload.ProductID)
+	if errors.Is(err, wishlist_service.ErrProductNotFound) {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to add to wishlist", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to add to wishlist", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusCreated, "Succeed to POST data", nil)
+}
+
+func (h *wishlistHandlerImpl) RemoveFromWishlist(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uint)
+	if !ok {
+		return utils.ErrorResponseFiber(c, http.StatusUnauthorized, "Invalid user token", "User ID not found in token")
+	}
+	productID, err := strconv.ParseUint(c.Params("product_id"), 10, 32)
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusBadRequest, "Invalid product ID", err.Error())
+	}
+
+	err = h.wishlistService.RemoveFromWishlist(userID, uint(productID))
+	if errors.Is(err, wishlist_service.ErrNotInWishlist) {
+		return utils.ErrorResponseFiber(c, http.StatusNotFound, "Failed to remove from wishlist", err.Error())
+	}
+	if err != nil {
+		return utils.ErrorResponseFiber(c, http.StatusInternalServerError, "Failed to remove from wishlist", err.Error())
+	}
+	return utils.SuccessResponseFiber(c, http.StatusOK, "Succeed to DELETE data", nil)
+}
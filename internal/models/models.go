@@ -6,6 +6,12 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	ResellerStatusPending  = "pending"
+	ResellerStatusApproved = "approved"
+	ResellerStatusRejected = "rejected"
+)
+
 type User struct {
 	gorm.Model
 	ID           uint       `gorm:"primaryKey;autoIncrement"`
@@ -20,8 +26,11 @@ type User struct {
 	IDProvinsi   uint
 	IDKota       uint
 	IsAdmin      bool `gorm:"type:boolean"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+
+	ResellerStatus     string `gorm:"type:varchar(20);index"`
+	ResellerApprovedAt *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 
 	Alamat []Alamat `gorm:"foreignKey:IDUser"`
 	Trx    []Trx    `gorm:"foreignKey:IDUser"`
@@ -35,8 +44,10 @@ type Alamat struct {
 	NamaPenerima string `gorm:"type:varchar(255)"`
 	NoTelp       string `gorm:"type:varchar(255)"`
 	DetailAlamat string `gorm:"type:varchar(255)"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	// IDKota adalah kota tujuan pengiriman; 0 berarti memakai IDKota user.
+	IDKota    uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
 
 	User User `gorm:"foreignKey:IDUser"`
 }
@@ -47,21 +58,39 @@ type Toko struct {
 	IDUser      uint
 	NamaToko    string `gorm:"type:varchar(255)"`
 	URLFotoToko string `gorm:"type:varchar(255)"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	WebhookURL  string `gorm:"type:varchar(255)"`
+	// WebhookSecret menandatangani webhook toko ini dan hanya bisa dilihat
+	// pemilik toko lewat endpoint webhook-secret.
+	WebhookSecret string `gorm:"type:varchar(64)" json:"-"`
+	RatingAvg     float64
+	RatingCount   int
+	// IDKota adalah kota asal pengiriman pesanan toko.
+	IDKota uint
+	// CODAktif menentukan apakah toko menerima COD. CODMaksTotal adalah
+	// total sub-order COD terbesar yang diterima, 0 berarti tanpa batas.
+	CODAktif     bool
+	CODMaksTotal int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	FotoTokoVariants map[string]string `gorm:"-"`
 
 	User       User         `gorm:"foreignKey:IDUser"`
 	Product    []Product    `gorm:"foreignKey:IDToko"`
 	ProductLog []ProductLog `gorm:"foreignKey:IDToko"`
 }
 
+// Category bisa bersarang tanpa batas kedalaman. ParentID nil berarti
+// kategori paling atas.
 type Category struct {
 	gorm.Model
 	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	ParentID     *uint  `gorm:"index"`
 	NamaCategory string `gorm:"type:varchar(255)"`
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 
+	Children   []Category   `gorm:"foreignKey:ParentID"`
 	Product    []Product    `gorm:"foreignKey:IDCategory"`
 	ProductLog []ProductLog `gorm:"foreignKey:IDCategory"`
 }
@@ -77,13 +106,160 @@ type Product struct {
 	HargaKonsumen int
 	Stok          int
 	Deskripsi     string `gorm:"type:text"`
+	// LowStockThreshold 0 berarti seller hanya diberi tahu saat stok habis.
+	LowStockThreshold int
+	AlertStok         string  `gorm:"type:varchar(20);not null;default:''"`
+	RatingAvg         float64 `gorm:"index"`
+	RatingCount       int
+	// BeratGram dan dimensi paket dalam cm dipakai untuk menghitung ongkir.
+	BeratGram int
+	PanjangCm int
+	LebarCm   int
+	TinggiCm  int
+	// Status produk; hanya produk published yang tampil di daftar publik dan
+	// bisa dibeli. PublishAt dan UnpublishAt dijalankan oleh scheduler.
+	Status      string `gorm:"type:varchar(20);not null;default:published;index"`
+	PublishAt   *time.Time
+	UnpublishAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// StokTersedia adalah Stok dikurangi reservasi checkout yang masih aktif.
+	StokTersedia int `gorm:"-"`
+	// IsWishlisted hanya diisi untuk user yang login.
+	IsWishlisted bool `gorm:"-"`
+	// Breadcrumbs berisi kategori dari yang paling atas sampai kategori
+	// produk, hanya diisi pada detail produk.
+	Breadcrumbs []Category `gorm:"-"`
+	// HargaEfektif adalah HargaKonsumen setelah diskon terbaik yang sedang
+	// berlaku, Diskon berisi diskon tersebut atau nil bila tidak ada.
+	HargaEfektif int       `gorm:"-"`
+	Diskon       *Discount `gorm:"-"`
+
+	Toko           Toko               `gorm:"foreignKey:IDToko"`
+	Category       Category           `gorm:"foreignKey:IDCategory"`
+	ProductPhoto   []ProductPhoto     `gorm:"foreignKey:ProductID"`
+	ProductVariant []ProductVariant   `gorm:"foreignKey:ProductID"`
+	PriceTier      []ProductPriceTier `gorm:"foreignKey:ProductID"`
+	Atribut        []ProductAttribute `gorm:"foreignKey:ProductID"`
+	DetailTrx      []DetailTrx        `gorm:"foreignKey:ProductID"`
+}
+
+// Tipe CategoryAttribute.
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeEnum    = "enum"
+	AttributeBoolean = "boolean"
+)
+
+// CategoryAttribute adalah atribut yang harus atau boleh diisi seller untuk
+// produk di kategori tersebut. Atribut kategori induk ikut berlaku untuk
+// subkategorinya. Pilihan hanya dipakai untuk tipe enum.
+type CategoryAttribute struct {
+	gorm.Model
+	ID         uint     `gorm:"primaryKey;autoIncrement"`
+	CategoryID uint     `gorm:"uniqueIndex:idx_category_attribute_kode"`
+	Kode       string   `gorm:"type:varchar(50);uniqueIndex:idx_category_attribute_kode"`
+	Nama       string   `gorm:"type:varchar(255)"`
+	Tipe       string   `gorm:"type:varchar(20)"`
+	Wajib      bool     `gorm:"type:boolean"`
+	Pilihan    []string `gorm:"serializer:json;type:text"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ProductAttribute menyimpan nilai atribut produk dalam bentuk teks yang
+// sudah dinormalisasi (angka tanpa nol berlebih, boolean "true"/"false")
+// supaya bisa difilter dengan perbandingan biasa.
+type ProductAttribute struct {
+	gorm.Model
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	ProductID uint   `gorm:"uniqueIndex:idx_product_attribute_kode"`
+	Kode      string `gorm:"type:varchar(50);uniqueIndex:idx_product_attribute_kode;index:idx_product_attribute_nilai"`
+	Nilai     string `gorm:"type:varchar(255);index:idx_product_attribute_nilai"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	ProductDraft     = "draft"
+	ProductPublished = "published"
+	ProductArchived  = "archived"
+)
+
+// Nilai Product.AlertStok, yaitu peringatan stok terakhir yang sudah
+// dikirim ke seller. Kosong berarti stok aman.
+const (
+	StockAlertLow = "low_stock"
+	StockAlertOut = "out_of_stock"
+)
+
+// ProductVariant adalah varian produk (misalnya ukuran dan warna) dengan
+// stok sendiri. Harga nil berarti memakai harga dari Product.
+type ProductVariant struct {
+	gorm.Model
+	ID            uint              `gorm:"primaryKey;autoIncrement"`
+	ProductID     uint              `gorm:"index"`
+	SKU           string            `gorm:"type:varchar(100);index"`
+	Opsi          map[string]string `gorm:"serializer:json;type:text"`
+	HargaReseller *int
+	HargaKonsumen *int
+	Stok          int
+	URLFoto       string `gorm:"type:varchar(255)"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 
-	Toko         Toko           `gorm:"foreignKey:IDToko"`
-	Category     Category       `gorm:"foreignKey:IDCategory"`
-	ProductPhoto []ProductPhoto `gorm:"foreignKey:ProductID"`
-	DetailTrx    []DetailTrx    `gorm:"foreignKey:ProductID"`
+	FotoVariants map[string]string `gorm:"-"`
+	StokTersedia int               `gorm:"-"`
+	HargaEfektif int               `gorm:"-"`
+
+	Product Product `gorm:"foreignKey:ProductID"`
+}
+
+// ProductPriceTier adalah harga grosir yang berlaku bila kuantitas satu
+// baris pembelian mencapai MinKuantitas.
+type ProductPriceTier struct {
+	gorm.Model
+	ID           uint `gorm:"primaryKey;autoIncrement"`
+	ProductID    uint `gorm:"index"`
+	MinKuantitas int
+	Harga        int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Product Product `gorm:"foreignKey:ProductID"`
+}
+
+// Tipe dan cakupan Discount.
+const (
+	DiscountPercent = "percent"
+	DiscountFixed   = "fixed"
+
+	DiscountScopeProduct  = "product"
+	DiscountScopeCategory = "category"
+	DiscountScopeToko     = "toko"
+)
+
+// Discount memotong harga konsumen produk, produk dalam kategori (beserta
+// subkategorinya) atau produk satu toko selama MulaiAt sampai SelesaiAt.
+// Kuota nil berarti tanpa batas; Terpakai adalah jumlah unit yang sudah
+// dibeli dengan diskon ini dan dikembalikan bila transaksinya batal.
+type Discount struct {
+	gorm.Model
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint   `gorm:"index"`
+	Nama      string `gorm:"type:varchar(255)"`
+	Tipe      string `gorm:"type:varchar(20)"`
+	Nilai     int
+	Cakupan   string    `gorm:"type:varchar(20);index:idx_discount_target"`
+	TargetID  uint      `gorm:"index:idx_discount_target"`
+	MulaiAt   time.Time `gorm:"index"`
+	SelesaiAt time.Time `gorm:"index"`
+	Kuota     *int
+	Terpakai  int `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type ProductPhoto struct {
@@ -94,6 +270,49 @@ type ProductPhoto struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
+	Variants map[string]string `gorm:"-"`
+
+	Product Product `gorm:"foreignKey:ProductID"`
+}
+
+// StoredFile mencatat file upload yang disimpan berdasarkan hash SHA-256
+// isinya. RefCount adalah jumlah baris ProductPhoto/Toko yang memakai Key.
+type StoredFile struct {
+	gorm.Model
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Hash      string `gorm:"type:varchar(64);uniqueIndex"`
+	Key       string `gorm:"type:varchar(255);uniqueIndex"`
+	RefCount  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	StockMovementSale         = "sale"
+	StockMovementCancellation = "cancellation"
+	StockMovementAdjustment   = "adjustment"
+	StockMovementReturn       = "return"
+	StockMovementImport       = "import"
+)
+
+// StockMovement adalah ledger append-only untuk setiap perubahan stok.
+// Jumlah bernilai negatif untuk stok keluar; StokAkhir adalah stok produk
+// (atau stok varian bila VariantID diisi) setelah pergerakan ini. IDUser
+// bernilai 0 untuk perubahan oleh sistem.
+type StockMovement struct {
+	gorm.Model
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	ProductID uint   `gorm:"index"`
+	VariantID *uint  `gorm:"index"`
+	Tipe      string `gorm:"type:varchar(20)"`
+	Jumlah    int
+	StokAkhir int
+	Alasan    string `gorm:"type:varchar(255)"`
+	IDUser    uint
+	IDTrx     *uint `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
 	Product Product `gorm:"foreignKey:ProductID"`
 }
 
@@ -116,6 +335,200 @@ type ProductLog struct {
 	Category Category `gorm:"foreignKey:IDCategory"`
 }
 
+// AuditLog mencatat perubahan data yang berdampak ke banyak pihak, misalnya
+// penghapusan kategori yang ikut memindahkan produk seller.
+type AuditLog struct {
+	gorm.Model
+	ID        uint                   `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint                   `gorm:"index"`
+	Aksi      string                 `gorm:"type:varchar(50)"`
+	Entitas   string                 `gorm:"type:varchar(50);index:idx_audit_entitas"`
+	EntitasID uint                   `gorm:"index:idx_audit_entitas"`
+	Detail    map[string]interface{} `gorm:"serializer:json;type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const (
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+// ImportRowError adalah kesalahan satu baris CSV. Row dihitung dari baris
+// pertama file (header), sama seperti nomor baris di spreadsheet.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// ImportJob mencatat progres import produk dari CSV yang diproses di
+// background. DryRun hanya memvalidasi tanpa membuat produk.
+type ImportJob struct {
+	gorm.Model
+	ID          uint `gorm:"primaryKey;autoIncrement"`
+	IDUser      uint `gorm:"index"`
+	IDToko      uint
+	NamaFile    string `gorm:"type:varchar(255)"`
+	Status      string `gorm:"type:varchar(20)"`
+	DryRun      bool
+	TotalRows   int
+	SuccessRows int
+	FailedRows  int
+	Errors      []ImportRowError `gorm:"serializer:json;type:text"`
+	Message     string           `gorm:"type:text"`
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Review hanya bisa dibuat dari DetailTrx milik pembeli yang sudah selesai,
+// satu review untuk setiap DetailTrx.
+type Review struct {
+	gorm.Model
+	ID          uint `gorm:"primaryKey;autoIncrement"`
+	ProductID   uint `gorm:"index"`
+	IDUser      uint `gorm:"index"`
+	IDDetailTrx uint `gorm:"uniqueIndex"`
+	Rating      int
+	Ulasan      string `gorm:"type:text"`
+	Balasan     string `gorm:"type:text"`
+	DibalasAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	User        User          `gorm:"foreignKey:IDUser"`
+	Product     Product       `gorm:"foreignKey:ProductID"`
+	ReviewPhoto []ReviewPhoto `gorm:"foreignKey:ReviewID"`
+}
+
+type ReviewPhoto struct {
+	gorm.Model
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	ReviewID  uint   `gorm:"index"`
+	URL       string `gorm:"type:varchar(255)"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Variants map[string]string `gorm:"-"`
+}
+
+// Wishlist dihapus permanen saat produk dikeluarkan dari wishlist supaya
+// unique index user dan produk tidak bentrok saat produk ditambahkan lagi.
+type Wishlist struct {
+	gorm.Model
+	ID        uint `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint `gorm:"uniqueIndex:idx_wishlist_user_product"`
+	ProductID uint `gorm:"uniqueIndex:idx_wishlist_user_product"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Product Product `gorm:"foreignKey:ProductID"`
+}
+
+// CartItem adalah isi keranjang user yang disimpan di server. Baris dihapus
+// permanen saat dikeluarkan dari keranjang atau di-checkout. HargaSatuan,
+// Subtotal dan Peringatan dihitung ulang setiap keranjang dilihat.
+type CartItem struct {
+	gorm.Model
+	ID        uint  `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint  `gorm:"index"`
+	ProductID uint  `gorm:"index"`
+	VariantID *uint `gorm:"index"`
+	Kuantitas int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	HargaSatuan int    `gorm:"-"`
+	Subtotal    int    `gorm:"-"`
+	Peringatan  string `gorm:"-"`
+
+	Product Product         `gorm:"foreignKey:ProductID"`
+	Variant *ProductVariant `gorm:"foreignKey:VariantID"`
+}
+
+// Cart adalah isi keranjang beserta total harganya. Total hanya
+// menjumlahkan item tanpa peringatan; harga akhir termasuk harga reseller,
+// grosir dan voucher dihitung saat preview atau checkout.
+type Cart struct {
+	Items []CartItem
+	Total int
+}
+
+// ShippingOption adalah ongkir satu kurir untuk semua paket dalam satu
+// checkout, dengan rincian per toko di PerToko.
+type ShippingOption struct {
+	Kurir   string
+	Ongkir  int
+	PerToko []ShippingQuote
+}
+
+type ShippingQuote struct {
+	IDToko   uint
+	BeratKg  int
+	Ongkir   int
+	Estimasi string
+}
+
+// SettlementReport merangkum pesanan toko yang sudah dibayar atau sedang
+// berjalan. Uang pesanan NonCOD diterima platform lewat payment provider,
+// sedangkan uang pesanan COD diterima seller langsung dari pembeli.
+type SettlementReport struct {
+	NonCOD SettlementSummary
+	COD    CODSummary
+}
+
+type SettlementSummary struct {
+	JumlahPesanan int
+	Total         int
+}
+
+// CODSummary memisahkan pesanan COD yang uangnya sudah dan belum
+// dikonfirmasi diterima seller.
+type CODSummary struct {
+	JumlahPesanan int
+	Total         int
+	Diterima      int
+	BelumDiterima int
+}
+
+// NotificationOrderUpdate dikirim ke pembeli saat seller mengubah status
+// pesanannya.
+const NotificationOrderUpdate = "order_update"
+
+type Notification struct {
+	gorm.Model
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	IDUser    uint   `gorm:"index"`
+	Tipe      string `gorm:"type:varchar(50)"`
+	Judul     string `gorm:"type:varchar(255)"`
+	Pesan     string `gorm:"type:text"`
+	ProductID *uint
+	ReadAt    *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User User `gorm:"foreignKey:IDUser"`
+}
+
+const (
+	TrxStatusPendingPayment = "pending_payment"
+	TrxStatusPaid           = "paid"
+	TrxStatusCancelled      = "cancelled"
+	// TrxStatusDelivered dipasang otomatis dari event pengiriman, pada Trx
+	// setelah semua sub-order yang dikirim sudah sampai.
+	TrxStatusDelivered = "delivered"
+	// Status berikut hanya dipakai TrxToko dan diubah oleh seller, kecuali
+	// processing yang juga menjadi status awal Trx COD.
+	TrxStatusProcessing = "processing"
+	TrxStatusShipped    = "shipped"
+	TrxStatusRejected   = "rejected"
+)
+
+// Trx yang dibuat sebelum ada reservasi stoknya langsung dipotong saat
+// checkout, karena itu default Status-nya paid.
 type Trx struct {
 	gorm.Model
 	ID               uint `gorm:"primaryKey;autoIncrement"`
@@ -124,37 +537,379 @@ type Trx struct {
 	KodeInvoice      string `gorm:"type:varchar(255)"`
 	MethodBayar      string `gorm:"type:varchar(255)"`
 	HargaTotal       int
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	Status           string `gorm:"type:varchar(30);default:paid;index"`
+	ExpiresAt        *time.Time
+	// KodeVoucher dan PotonganVoucher diisi bila checkout memakai voucher.
+	// HargaTotal sudah dikurangi PotonganVoucher.
+	KodeVoucher     string `gorm:"type:varchar(50)"`
+	PotonganVoucher int
+	// Kurir dan Ongkir diisi bila pembeli memilih kurir. Ongkir adalah total
+	// ongkir semua sub-order dan sudah termasuk di HargaTotal.
+	Kurir     string `gorm:"type:varchar(50)"`
+	Ongkir    int
+	CreatedAt time.Time
+	UpdatedAt time.Time
 
-	User      User        `gorm:"foreignKey:IDUser"`
-	Alamat    Alamat      `gorm:"foreignKey:AlamatPengiriman"`
-	DetailTrx []DetailTrx `gorm:"foreignKey:IDTrx"`
+	User              User               `gorm:"foreignKey:IDUser"`
+	Alamat            Alamat             `gorm:"foreignKey:AlamatPengiriman"`
+	DetailTrx         []DetailTrx        `gorm:"foreignKey:IDTrx"`
+	TrxToko           []TrxToko          `gorm:"foreignKey:IDTrx"`
+	VoucherRedemption *VoucherRedemption `gorm:"foreignKey:IDTrx"`
+	Payment           *Payment           `gorm:"foreignKey:IDTrx"`
 }
 
-type DetailTrx struct {
+// TrxToko adalah bagian Trx untuk satu toko yang diproses seller toko
+// tersebut secara terpisah dengan status sendiri. HargaTotal adalah
+// Subtotal item toko dikurangi bagian PotonganVoucher yang dibebankan ke
+// toko ini, ditambah Ongkir paket dari toko ini.
+type TrxToko struct {
 	gorm.Model
-	ID         uint `gorm:"primaryKey;autoIncrement"`
-	IDTrx      uint
-	ProductID  uint
-	IDToko     uint
-	Kuantitas  int
-	HargaTotal int
+	ID              uint   `gorm:"primaryKey;autoIncrement"`
+	IDTrx           uint   `gorm:"uniqueIndex:idx_trx_toko"`
+	IDToko          uint   `gorm:"uniqueIndex:idx_trx_toko;index"`
+	KodeInvoice     string `gorm:"type:varchar(255)"`
+	Subtotal        int
+	PotonganVoucher int
+	Ongkir          int
+	EstimasiKirim   string `gorm:"type:varchar(50)"`
+	HargaTotal      int
+	Status          string `gorm:"type:varchar(30);index"`
+	// NoResi dan ShippedAt diisi saat seller mengirim pesanan, AlasanTolak
+	// saat seller menolaknya, dan CODCollectedAt saat seller menerima uang
+	// pesanan COD.
+	NoResi         string `gorm:"type:varchar(100)"`
+	ShippedAt      *time.Time
+	AlasanTolak    string `gorm:"type:varchar(255)"`
+	CODCollectedAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	Trx       Trx         `gorm:"foreignKey:IDTrx"`
+	Toko      Toko        `gorm:"foreignKey:IDToko"`
+	DetailTrx []DetailTrx `gorm:"foreignKey:IDTrxToko"`
+	Shipment  *Shipment   `gorm:"foreignKey:IDTrxToko"`
+}
+
+const (
+	ShipmentShipped        = "shipped"
+	ShipmentInTransit      = "in_transit"
+	ShipmentOutForDelivery = "out_for_delivery"
+	ShipmentFailedAttempt  = "failed_attempt"
+	ShipmentDelivered      = "delivered"
+)
+
+const (
+	ShipmentSourceSeller  = "seller"
+	ShipmentSourceCourier = "courier"
+)
+
+// Shipment dibuat saat seller mengirim sub-order. Status mengikuti event
+// terakhir; event delivered adalah event terakhir dan menandai sub-order
+// sebagai delivered.
+type Shipment struct {
+	gorm.Model
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	IDTrxToko   uint   `gorm:"uniqueIndex"`
+	IDTrx       uint   `gorm:"index"`
+	Kurir       string `gorm:"type:varchar(50);index:idx_shipment_resi"`
+	NoResi      string `gorm:"type:varchar(100);index:idx_shipment_resi"`
+	Status      string `gorm:"type:varchar(30)"`
+	DeliveredAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	TrxToko *TrxToko        `gorm:"foreignKey:IDTrxToko"`
+	Events  []ShipmentEvent `gorm:"foreignKey:IDShipment"`
+}
+
+// ShipmentEvent adalah satu baris timeline pengiriman. EventID diisi dari
+// webhook kurir supaya event yang dikirim ulang tidak tercatat dua kali.
+type ShipmentEvent struct {
+	gorm.Model
+	ID         uint    `gorm:"primaryKey;autoIncrement"`
+	IDShipment uint    `gorm:"uniqueIndex:idx_shipment_event"`
+	EventID    *string `gorm:"type:varchar(100);uniqueIndex:idx_shipment_event"`
+	Status     string  `gorm:"type:varchar(30)"`
+	Keterangan string  `gorm:"type:varchar(255)"`
+	Lokasi     string  `gorm:"type:varchar(255)"`
+	Sumber     string  `gorm:"type:varchar(20)"`
+	Waktu      time.Time
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+}
 
-	Trx     Trx        `gorm:"foreignKey:IDTrx"`
-	Product ProductLog `gorm:"foreignKey:ProductID"`
-	Toko    Toko       `gorm:"foreignKey:IDToko"`
+// PaymentMethodCOD adalah method_bayar untuk bayar di tempat. Trx COD
+// tidak punya Payment dan langsung diproses seller.
+const PaymentMethodCOD = "cod"
+
+const (
+	PaymentPending = "pending"
+	PaymentPaid    = "paid"
+	PaymentFailed  = "failed"
+)
+
+// Payment adalah tagihan satu Trx di payment provider. Trx hanya menjadi
+// paid lewat callback provider yang terverifikasi. JumlahRefund adalah
+// total yang sudah dikembalikan ke pembeli, sedangkan JumlahRefundTertunda
+// adalah refund yang sudah dicatat tetapi belum berhasil dikirim ke
+// provider dan akan dicoba ulang.
+type Payment struct {
+	gorm.Model
+	ID                   uint   `gorm:"primaryKey;autoIncrement"`
+	IDTrx                uint   `gorm:"uniqueIndex"`
+	Provider             string `gorm:"type:varchar(30);uniqueIndex:idx_payment_provider_reference"`
+	Reference            string `gorm:"type:varchar(100);uniqueIndex:idx_payment_provider_reference"`
+	Jumlah               int
+	Status               string `gorm:"type:varchar(20)"`
+	Instruksi            string `gorm:"type:text"`
+	PaidAt               *time.Time
+	JumlahRefund         int
+	JumlahRefundTertunda int
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+
+	Trx *Trx `gorm:"foreignKey:IDTrx"`
+}
+
+// PaymentCallback mencatat callback provider yang sudah diproses supaya
+// callback yang dikirim ulang tidak diproses dua kali.
+type PaymentCallback struct {
+	gorm.Model
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	IDPayment uint   `gorm:"index"`
+	Provider  string `gorm:"type:varchar(30);uniqueIndex:idx_payment_callback"`
+	EventID   string `gorm:"type:varchar(100);uniqueIndex:idx_payment_callback"`
+	Status    string `gorm:"type:varchar(20)"`
+	Jumlah    int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Voucher dengan IDToko nil diterbitkan admin dan berlaku untuk seluruh
+// belanja, sedangkan voucher toko hanya memotong item dari toko tersebut.
+// Kuota dan KuotaPerUser nil berarti tanpa batas; Terpakai adalah jumlah
+// redemption yang masih berlaku.
+type Voucher struct {
+	gorm.Model
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	Kode         string `gorm:"type:varchar(50);uniqueIndex"`
+	IDUser       uint   `gorm:"index"`
+	IDToko       *uint  `gorm:"index"`
+	Tipe         string `gorm:"type:varchar(20)"`
+	Nilai        int
+	MinBelanja   int
+	MaksPotongan *int
+	Kuota        *int
+	KuotaPerUser *int
+	Terpakai     int `gorm:"not null;default:0"`
+	MulaiAt      time.Time
+	SelesaiAt    time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	Toko *Toko `gorm:"foreignKey:IDToko"`
+}
+
+const (
+	VoucherRedeemed = "redeemed"
+	VoucherReleased = "released"
+)
+
+// VoucherRedemption mencatat pemakaian voucher oleh satu transaksi. Status
+// menjadi released saat transaksinya batal sehingga kuota voucher kembali.
+type VoucherRedemption struct {
+	gorm.Model
+	ID        uint `gorm:"primaryKey;autoIncrement"`
+	IDVoucher uint `gorm:"index:idx_voucher_redemption_user"`
+	IDUser    uint `gorm:"index:idx_voucher_redemption_user"`
+	IDTrx     uint `gorm:"uniqueIndex"`
+	Potongan  int
+	Status    string `gorm:"type:varchar(20);index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Voucher Voucher `gorm:"foreignKey:IDVoucher"`
+}
+
+const (
+	ReservationActive   = "active"
+	ReservationReleased = "released"
+	ReservationConsumed = "consumed"
+)
+
+// StockReservation menahan stok untuk transaksi yang belum dibayar sampai
+// ExpiresAt. Reservasi aktif mengurangi stok tersedia tanpa mengubah
+// Stok, baru dipotong lewat ledger saat reservasi dikonsumsi.
+type StockReservation struct {
+	gorm.Model
+	ID        uint  `gorm:"primaryKey;autoIncrement"`
+	IDTrx     uint  `gorm:"index"`
+	ProductID uint  `gorm:"index"`
+	VariantID *uint `gorm:"index"`
+	Kuantitas int
+	Status    string    `gorm:"type:varchar(20);index"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Trx     Trx     `gorm:"foreignKey:IDTrx"`
+	Product Product `gorm:"foreignKey:ProductID"`
+}
+
+const (
+	TierHargaKonsumen = "konsumen"
+	TierHargaReseller = "reseller"
+	TierHargaGrosir   = "grosir"
+	TierHargaDiskon   = "diskon"
+)
+
+type DetailTrx struct {
+	gorm.Model
+	ID           uint `gorm:"primaryKey;autoIncrement"`
+	IDTrx        uint
+	ProductID    uint
+	VariantID    *uint
+	IDProductLog uint
+	IDToko       uint
+	Kuantitas    int
+	HargaSatuan  int
+	TierHarga    string `gorm:"type:varchar(20)"`
+	HargaTotal   int
+	// IDDiscount dan Potongan diisi bila harga satuan berasal dari diskon.
+	// Potongan adalah selisih total baris dengan harga tanpa diskon.
+	IDDiscount *uint `gorm:"index"`
+	Potongan   int
+	// IDTrxToko nil untuk transaksi yang dibuat sebelum ada sub-order toko.
+	IDTrxToko *uint `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Trx        Trx             `gorm:"foreignKey:IDTrx"`
+	Product    Product         `gorm:"foreignKey:ProductID"`
+	Variant    *ProductVariant `gorm:"foreignKey:VariantID"`
+	ProductLog ProductLog      `gorm:"foreignKey:IDProductLog"`
+	Toko       Toko            `gorm:"foreignKey:IDToko"`
+	Discount   *Discount       `gorm:"foreignKey:IDDiscount"`
 }
 
 type DetailTrxPayload struct {
-	ProductID uint `json:"product_id"`
-	Kuantitas int  `json:"kuantitas"`
+	ProductID uint  `json:"product_id"`
+	VariantID *uint `json:"variant_id"`
+	Kuantitas int   `json:"kuantitas"`
+}
+
+type ProductPriceTierPayload struct {
+	MinKuantitas int `json:"min_kuantitas"`
+	Harga        int `json:"harga"`
+}
+
+type ReviewPayload struct {
+	IDDetailTrx uint   `json:"id_detail_trx" form:"id_detail_trx"`
+	Rating      int    `json:"rating" form:"rating"`
+	Ulasan      string `json:"ulasan" form:"ulasan"`
+}
+
+type ReviewReplyPayload struct {
+	Balasan string `json:"balasan" form:"balasan"`
+}
+
+type WishlistPayload struct {
+	ProductID uint `json:"product_id" form:"product_id"`
+}
+
+type CategoryAttributePayload struct {
+	Kode    string   `json:"kode"`
+	Nama    string   `json:"nama"`
+	Tipe    string   `json:"tipe"`
+	Wajib   bool     `json:"wajib"`
+	Pilihan []string `json:"pilihan"`
+}
+
+type DiscountPayload struct {
+	Nama      string    `json:"nama"`
+	Tipe      string    `json:"tipe"`
+	Nilai     int       `json:"nilai"`
+	Cakupan   string    `json:"cakupan"`
+	TargetID  uint      `json:"target_id"`
+	MulaiAt   time.Time `json:"mulai_at"`
+	SelesaiAt time.Time `json:"selesai_at"`
+	Kuota     *int      `json:"kuota"`
+}
+
+type VoucherPayload struct {
+	Kode         string    `json:"kode"`
+	IDToko       *uint     `json:"id_toko"`
+	Tipe         string    `json:"tipe"`
+	Nilai        int       `json:"nilai"`
+	MinBelanja   int       `json:"min_belanja"`
+	MaksPotongan *int      `json:"maks_potongan"`
+	Kuota        *int      `json:"kuota"`
+	KuotaPerUser *int      `json:"kuota_per_user"`
+	MulaiAt      time.Time `json:"mulai_at"`
+	SelesaiAt    time.Time `json:"selesai_at"`
+}
+
+type ShipOrderPayload struct {
+	NoResi string `json:"no_resi"`
+}
+
+// ShipmentEventPayload dipakai seller dan webhook kurir. NoResi dan EventID
+// hanya dibaca dari webhook; Waktu kosong berarti saat event diterima.
+type ShipmentEventPayload struct {
+	NoResi     string     `json:"no_resi"`
+	EventID    string     `json:"event_id"`
+	Status     string     `json:"status"`
+	Keterangan string     `json:"keterangan"`
+	Lokasi     string     `json:"lokasi"`
+	Waktu      *time.Time `json:"waktu"`
+}
+
+type RejectOrderPayload struct {
+	Alasan string `json:"alasan"`
 }
 
+type TokoWebhookSecret struct {
+	Secret string `json:"secret"`
+}
+
+type TokoCODPayload struct {
+	Aktif     bool `json:"aktif"`
+	MaksTotal int  `json:"maks_total"`
+}
+
+type CartItemPayload struct {
+	ProductID uint  `json:"product_id"`
+	VariantID *uint `json:"variant_id"`
+	Kuantitas int   `json:"kuantitas"`
+}
+
+type CartCheckoutPayload struct {
+	MethodBayar string `json:"method_bayar"`
+	AlamatKirim uint   `json:"alamat_kirim"`
+	VoucherCode string `json:"voucher_code"`
+	Kurir       string `json:"kurir"`
+}
+
+type ProductVariantPayload struct {
+	SKU           string `json:"sku" form:"sku"`
+	Opsi          string `json:"opsi" form:"opsi"`
+	HargaReseller *int   `json:"harga_reseller" form:"harga_reseller"`
+	HargaKonsumen *int   `json:"harga_konsumen" form:"harga_konsumen"`
+	Stok          int    `json:"stok" form:"stok"`
+}
+
+// TrxPayload dengan Kurir kosong berarti pesanan tidak dikirim sehingga
+// tanpa ongkir. MethodBayar adalah kode payment provider, misalnya
+// bank_transfer, atau cod.
 type TrxPayload struct {
 	MethodBayar string             `json:"method_bayar"`
+	AlamatKirim uint               `json:"alamat_kirim"`
+	VoucherCode string             `json:"voucher_code"`
+	Kurir       string             `json:"kurir"`
+	DetailTrx   []DetailTrxPayload `json:"detail_trx"`
+}
+
+type ShippingQuotePayload struct {
 	AlamatKirim uint               `json:"alamat_kirim"`
 	DetailTrx   []DetailTrxPayload `json:"detail_trx"`
 }
@@ -0,0 +1,28 @@
+package alamat_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AlamatRepository interface {
+	FindByIDAndUserID(id, userID uint) (*models.Alamat, error)
+}
+
+type alamatRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAlamatRepository(db *gorm.DB) AlamatRepository {
+	return &alamatRepositoryImpl{db: db}
+}
+
+func (r *alamatRepositoryImpl) FindByIDAndUserID(id, userID uint) (*models.Alamat, error) {
+	var alamat models.Alamat
+	err := r.db.Where("id = ? AND id_user = ?", id, userID).First(&alamat).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &alamat, err
+}
@@ -0,0 +1,13 @@
+package audit_log_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RecordTx mencatat audit log di dalam transaksi tx, supaya log hanya
+// tersimpan bila perubahan yang dicatat ikut tersimpan.
+func RecordTx(tx *gorm.DB, entry *models.AuditLog) error {
+	return tx.Create(entry).Error
+}
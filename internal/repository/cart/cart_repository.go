@@ -0,0 +1,82 @@
+package cart_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CartRepository interface {
+	Create(item *models.CartItem) error
+	Update(item *models.CartItem) error
+	FindByUserID(userID uint) ([]models.CartItem, error)
+	FindByID(userID, id uint) (*models.CartItem, error)
+	FindItem(userID, productID uint, variantID *uint) (*models.CartItem, error)
+	Delete(userID, id uint) (bool, error)
+	DeleteByIDs(userID uint, ids []uint) error
+	Clear(userID uint) error
+}
+
+type cartRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepositoryImpl{db: db}
+}
+
+func (r *cartRepositoryImpl) Create(item *models.CartItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *cartRepositoryImpl) Update(item *models.CartItem) error {
+	return r.db.Model(item).Update("kuantitas", item.Kuantitas).Error
+}
+
+func (r *cartRepositoryImpl) FindByUserID(userID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+	err := r.db.Where("id_user = ?", userID).Order("id").Find(&items).Error
+	return items, err
+}
+
+func (r *cartRepositoryImpl) FindByID(userID, id uint) (*models.CartItem, error) {
+	var item models.CartItem
+	err := r.db.Where("id = ? AND id_user = ?", id, userID).First(&item).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &item, err
+}
+
+// FindItem mencari baris keranjang untuk produk dan varian yang sama.
+func (r *cartRepositoryImpl) FindItem(userID, productID uint, variantID *uint) (*models.CartItem, error) {
+	query := r.db.Where("id_user = ? AND product_id = ?", userID, productID)
+	if variantID == nil {
+		query = query.Where("variant_id IS NULL")
+	} else {
+		query = query.Where("variant_id = ?", *variantID)
+	}
+	var item models.CartItem
+	err := query.First(&item).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &item, err
+}
+
+// Delete mengembalikan false bila item tidak ada di keranjang user.
+func (r *cartRepositoryImpl) Delete(userID, id uint) (bool, error) {
+	res := r.db.Unscoped().Where("id = ? AND id_user = ?", id, userID).Delete(&models.CartItem{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *cartRepositoryImpl) DeleteByIDs(userID uint, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Unscoped().Where("id IN ? AND id_user = ?", ids, userID).Delete(&models.CartItem{}).Error
+}
+
+func (r *cartRepositoryImpl) Clear(userID uint) error {
+	return r.db.Unscoped().Where("id_user = ?", userID).Delete(&models.CartItem{}).Error
+}
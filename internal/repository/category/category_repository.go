@@ -1,17 +1,23 @@
 package category_repository
 
 import (
+	"errors"
+
 	"test-rakamin/internal/models"
+	audit_log_repository "test-rakamin/internal/repository/audit_log"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrCategoryInUse = errors.New("category still has products or subcategories")
+
 type CategoryRepository interface {
 	Create(category *models.Category) error
 	FindAll() ([]models.Category, error)
 	FindByID(id uint) (*models.Category, error)
 	Update(category *models.Category) error
-	Delete(id uint) error
+	Delete(id, userID uint, moveTo *uint) error
 }
 
 type categoryRepositoryImpl struct {
@@ -45,6 +51,60 @@ func (r *categoryRepositoryImpl) Update(category *models.Category) error {
 	return r.db.Save(category).Error
 }
 
-func (r *categoryRepositoryImpl) Delete(id uint) error {
-	return r.db.Delete(&models.Category{}, id).Error
+// Delete menghapus kategori beserta audit log-nya dalam satu transaksi.
+// Bila moveTo nil dan kategori masih dipakai produk atau subkategori,
+// Delete mengembalikan ErrCategoryInUse. Bila moveTo diisi, produk dan
+// subkategori dipindahkan ke kategori tersebut lebih dulu.
+func (r *categoryRepositoryImpl) Delete(id, userID uint, moveTo *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, id).Error; err != nil {
+			return err
+		}
+
+		products := tx.Model(&models.Product{}).Where("id_category = ?", id)
+		children := tx.Model(&models.Category{}).Where("parent_id = ?", id)
+		var movedProducts, movedChildren int64
+		if moveTo == nil {
+			if err := products.Count(&movedProducts).Error; err != nil {
+				return err
+			}
+			if err := children.Count(&movedChildren).Error; err != nil {
+				return err
+			}
+			if movedProducts > 0 || movedChildren > 0 {
+				return ErrCategoryInUse
+			}
+		} else {
+			res := products.Update("id_category", *moveTo)
+			if res.Error != nil {
+				return res.Error
+			}
+			movedProducts = res.RowsAffected
+			res = children.Update("parent_id", *moveTo)
+			if res.Error != nil {
+				return res.Error
+			}
+			movedChildren = res.RowsAffected
+		}
+
+		if err := tx.Delete(&category).Error; err != nil {
+			return err
+		}
+		detail := map[string]interface{}{
+			"nama_category":  category.NamaCategory,
+			"moved_products": movedProducts,
+			"moved_children": movedChildren,
+		}
+		if moveTo != nil {
+			detail["move_to"] = *moveTo
+		}
+		return audit_log_repository.RecordTx(tx, &models.AuditLog{
+			IDUser:    userID,
+			Aksi:      "delete",
+			Entitas:   "category",
+			EntitasID: id,
+			Detail:    detail,
+		})
+	})
 }
@@ -0,0 +1,65 @@
+package category_attribute_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CategoryAttributeRepository interface {
+	Create(attribute *models.CategoryAttribute) error
+	FindByID(id uint) (*models.CategoryAttribute, error)
+	FindByCategoryIDs(categoryIDs []uint) ([]models.CategoryAttribute, error)
+	FindByKode(categoryID uint, kode string) (*models.CategoryAttribute, error)
+	Update(attribute *models.CategoryAttribute) error
+	Delete(id uint) error
+}
+
+type categoryAttributeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewCategoryAttributeRepository(db *gorm.DB) CategoryAttributeRepository {
+	return &categoryAttributeRepositoryImpl{db: db}
+}
+
+func (r *categoryAttributeRepositoryImpl) Create(attribute *models.CategoryAttribute) error {
+	return r.db.Create(attribute).Error
+}
+
+func (r *categoryAttributeRepositoryImpl) FindByID(id uint) (*models.CategoryAttribute, error) {
+	var attribute models.CategoryAttribute
+	err := r.db.First(&attribute, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &attribute, err
+}
+
+func (r *categoryAttributeRepositoryImpl) FindByCategoryIDs(categoryIDs []uint) ([]models.CategoryAttribute, error) {
+	var attributes []models.CategoryAttribute
+	if len(categoryIDs) == 0 {
+		return attributes, nil
+	}
+	err := r.db.Where("category_id IN ?", categoryIDs).Order("id").Find(&attributes).Error
+	return attributes, err
+}
+
+func (r *categoryAttributeRepositoryImpl) FindByKode(categoryID uint, kode string) (*models.CategoryAttribute, error) {
+	var attribute models.CategoryAttribute
+	err := r.db.Where("category_id = ? AND kode = ?", categoryID, kode).First(&attribute).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &attribute, err
+}
+
+func (r *categoryAttributeRepositoryImpl) Update(attribute *models.CategoryAttribute) error {
+	return r.db.Save(attribute).Error
+}
+
+// Delete menghapus permanen supaya kode yang sama bisa dipakai lagi di
+// kategori tersebut.
+func (r *categoryAttributeRepositoryImpl) Delete(id uint) error {
+	return r.db.Unscoped().Delete(&models.CategoryAttribute{}, id).Error
+}
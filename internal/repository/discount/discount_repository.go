@@ -0,0 +1,86 @@
+package discount_repository
+
+import (
+	"errors"
+	"time"
+
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrQuotaExhausted = errors.New("discount quota exhausted")
+
+type DiscountRepository interface {
+	Create(discount *models.Discount) error
+	FindByID(id uint) (*models.Discount, error)
+	FindActive(now time.Time) ([]models.Discount, error)
+	FindByUserID(userID uint) ([]models.Discount, error)
+	Delete(id uint) error
+}
+
+type discountRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewDiscountRepository(db *gorm.DB) DiscountRepository {
+	return &discountRepositoryImpl{db: db}
+}
+
+func (r *discountRepositoryImpl) Create(discount *models.Discount) error {
+	return r.db.Create(discount).Error
+}
+
+func (r *discountRepositoryImpl) FindByID(id uint) (*models.Discount, error) {
+	var discount models.Discount
+	err := r.db.First(&discount, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &discount, err
+}
+
+// FindActive mengembalikan diskon yang sedang berlaku pada now dan
+// kuotanya belum habis.
+func (r *discountRepositoryImpl) FindActive(now time.Time) ([]models.Discount, error) {
+	var discounts []models.Discount
+	err := r.db.Where("mulai_at <= ? AND selesai_at > ? AND (kuota IS NULL OR terpakai < kuota)", now, now).
+		Order("selesai_at").
+		Find(&discounts).Error
+	return discounts, err
+}
+
+func (r *discountRepositoryImpl) FindByUserID(userID uint) ([]models.Discount, error) {
+	var discounts []models.Discount
+	err := r.db.Where("id_user = ?", userID).Order("mulai_at DESC").Find(&discounts).Error
+	return discounts, err
+}
+
+func (r *discountRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&models.Discount{}, id).Error
+}
+
+// ClaimTx memakai kuantitas unit dari kuota diskon di dalam tx. Terpakai
+// dinaikkan dengan update bersyarat sehingga checkout yang berjalan
+// bersamaan tidak bisa melewati kuota, dan diskon yang sudah berakhir atau
+// dihapus tidak bisa diklaim lagi.
+func ClaimTx(tx *gorm.DB, id uint, kuantitas int, now time.Time) error {
+	res := tx.Model(&models.Discount{}).
+		Where("id = ? AND mulai_at <= ? AND selesai_at > ?", id, now, now).
+		Where("kuota IS NULL OR terpakai + ? <= kuota", kuantitas).
+		Update("terpakai", gorm.Expr("terpakai + ?", kuantitas))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrQuotaExhausted
+	}
+	return nil
+}
+
+// ReleaseTx mengembalikan kuota yang dipakai transaksi yang dibatalkan.
+func ReleaseTx(tx *gorm.DB, id uint, kuantitas int) error {
+	return tx.Unscoped().Model(&models.Discount{}).
+		Where("id = ?", id).
+		Update("terpakai", gorm.Expr("CASE WHEN terpakai > ? THEN terpakai - ? ELSE 0 END", kuantitas, kuantitas)).Error
+}
@@ -0,0 +1,55 @@
+package import_job_repository
+
+import (
+	"time"
+
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(job *models.ImportJob) error
+	FindByIDAndUserID(id, userID uint) (*models.ImportJob, error)
+	Update(job *models.ImportJob) error
+	FailUnfinished(message string) (int64, error)
+}
+
+type importJobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepositoryImpl{db: db}
+}
+
+func (r *importJobRepositoryImpl) Create(job *models.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *importJobRepositoryImpl) FindByIDAndUserID(id, userID uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.Where("id = ? AND id_user = ?", id, userID).First(&job).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &job, err
+}
+
+func (r *importJobRepositoryImpl) Update(job *models.ImportJob) error {
+	return r.db.Save(job).Error
+}
+
+// FailUnfinished menandai job yang masih pending atau running sebagai
+// gagal. Dipanggil saat aplikasi start karena job tersebut terhenti
+// bersama proses sebelumnya.
+func (r *importJobRepositoryImpl) FailUnfinished(message string) (int64, error) {
+	res := r.db.Model(&models.ImportJob{}).
+		Where("status IN ?", []string{models.ImportJobPending, models.ImportJobRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ImportJobFailed,
+			"message":     message,
+			"finished_at": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,61 @@
+package notification_repository
+
+import (
+	"time"
+
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	Create(notification *models.Notification) error
+	FindByUserID(userID uint, unreadOnly bool) ([]models.Notification, error)
+	MarkRead(id, userID uint) (bool, error)
+	MarkAllRead(userID uint) error
+}
+
+type notificationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepositoryImpl{db: db}
+}
+
+func (r *notificationRepositoryImpl) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *notificationRepositoryImpl) FindByUserID(userID uint, unreadOnly bool) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := r.db.Where("id_user = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	err := query.Order("id DESC").Find(&notifications).Error
+	return notifications, err
+}
+
+// MarkRead mengembalikan false bila notifikasi tidak ditemukan atau bukan
+// milik userID.
+func (r *notificationRepositoryImpl) MarkRead(id, userID uint) (bool, error) {
+	var notification models.Notification
+	err := r.db.Where("id = ? AND id_user = ?", id, userID).First(&notification).Error
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if notification.ReadAt != nil {
+		return true, nil
+	}
+	return true, r.db.Model(&notification).Update("read_at", time.Now()).Error
+}
+
+func (r *notificationRepositoryImpl) MarkAllRead(userID uint) error {
+	return r.db.Model(&models.Notification{}).
+		Where("id_user = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
@@ -0,0 +1,192 @@
+package payment_repository
+
+import (
+	"errors"
+	"time"
+
+	"test-rakamin/internal/models"
+	stock_reservation_repository "test-rakamin/internal/repository/stock_reservation"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDuplicateCallback dikembalikan ApplyCallback bila EventID callback
+	// sudah pernah diproses.
+	ErrDuplicateCallback = errors.New("payment callback has already been processed")
+	// ErrTrxClosed dikembalikan ApplyCallback bila pembayaran diterima
+	// setelah Trx tidak lagi menunggu pembayaran. Payment tetap tercatat
+	// paid dan seluruh dananya dicatat sebagai refund tertunda.
+	ErrTrxClosed = errors.New("transaction is no longer awaiting payment")
+)
+
+type PaymentRepository interface {
+	Create(payment *models.Payment) error
+	FindByTrxID(trxID uint) (*models.Payment, error)
+	FindByReference(provider, reference string) (*models.Payment, error)
+	ApplyCallback(payment *models.Payment, callback *models.PaymentCallback) (bool, error)
+	FindPendingRefunds() ([]models.Payment, error)
+	SettleRefund(paymentID uint, send func(payment *models.Payment, jumlah int) error) error
+}
+
+type paymentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepositoryImpl{db: db}
+}
+
+func (r *paymentRepositoryImpl) Create(payment *models.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *paymentRepositoryImpl) FindByTrxID(trxID uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Where("id_trx = ?", trxID).First(&payment).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &payment, err
+}
+
+// FindByReference mengembalikan payment dengan provider dan reference
+// tersebut. Pasangan keduanya unik.
+func (r *paymentRepositoryImpl) FindByReference(provider, reference string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Preload("Trx").Where("provider = ? AND reference = ?", provider, reference).First(&payment).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &payment, err
+}
+
+// ApplyCallback mencatat callback lalu mengubah status payment dalam satu
+// database transaction. Callback paid juga menandai Trx dan sub-order-nya
+// paid dan memotong stok yang direservasi, tetapi hanya bila Trx masih
+// pending_payment. Hasil true berarti Trx baru saja menjadi paid.
+func (r *paymentRepositoryImpl) ApplyCallback(payment *models.Payment, callback *models.PaymentCallback) (bool, error) {
+	paid, closed := false, false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		callback.IDPayment = payment.ID
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(callback)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrDuplicateCallback
+		}
+
+		// Payment dikunci supaya dua callback paid bersamaan tidak
+		// sama-sama memotong stok.
+		var current models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, payment.ID).Error; err != nil {
+			return err
+		}
+		payment.Status, payment.PaidAt, payment.JumlahRefund = current.Status, current.PaidAt, current.JumlahRefund
+		if current.Status == models.PaymentPaid {
+			return nil
+		}
+
+		if callback.Status != models.PaymentPaid {
+			payment.Status = models.PaymentFailed
+			return tx.Model(&models.Payment{}).Where("id = ?", payment.ID).Update("status", payment.Status).Error
+		}
+
+		now := time.Now()
+		payment.Status = models.PaymentPaid
+		payment.PaidAt = &now
+		err := tx.Model(&models.Payment{}).Where("id = ?", payment.ID).
+			Updates(map[string]interface{}{"status": payment.Status, "paid_at": payment.PaidAt}).Error
+		if err != nil {
+			return err
+		}
+
+		res = tx.Model(&models.Trx{}).
+			Where("id = ? AND status = ?", payment.IDTrx, models.TrxStatusPendingPayment).
+			Update("status", models.TrxStatusPaid)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			closed = true
+			return addPendingRefundTx(tx, &current, current.Jumlah)
+		}
+		err = tx.Model(&models.TrxToko{}).
+			Where("id_trx = ? AND status = ?", payment.IDTrx, models.TrxStatusPendingPayment).
+			Update("status", models.TrxStatusPaid).Error
+		if err != nil {
+			return err
+		}
+
+		var trx models.Trx
+		if err := tx.First(&trx, payment.IDTrx).Error; err != nil {
+			return err
+		}
+		paid = true
+		return stock_reservation_repository.ConsumeTx(tx, &trx)
+	})
+	if err == nil && closed {
+		err = ErrTrxClosed
+	}
+	return paid, err
+}
+
+// AddPendingRefundTx mencatat refund tertunda untuk pembayaran paid milik
+// trxID di dalam tx, paling banyak sisa dana yang belum dikembalikan atau
+// dicatat. Trx tanpa pembayaran paid (misalnya COD) tidak diproses.
+func AddPendingRefundTx(tx *gorm.DB, trxID uint, jumlah int) error {
+	var payment models.Payment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id_trx = ? AND status = ?", trxID, models.PaymentPaid).
+		First(&payment).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return addPendingRefundTx(tx, &payment, jumlah)
+}
+
+func addPendingRefundTx(tx *gorm.DB, payment *models.Payment, jumlah int) error {
+	jumlah = min(jumlah, payment.Jumlah-payment.JumlahRefund-payment.JumlahRefundTertunda)
+	if jumlah <= 0 {
+		return nil
+	}
+	payment.JumlahRefundTertunda += jumlah
+	return tx.Model(&models.Payment{}).Where("id = ?", payment.ID).
+		Update("jumlah_refund_tertunda", gorm.Expr("jumlah_refund_tertunda + ?", jumlah)).Error
+}
+
+func (r *paymentRepositoryImpl) FindPendingRefunds() ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.Where("jumlah_refund_tertunda > 0").Order("id").Find(&payments).Error
+	return payments, err
+}
+
+// SettleRefund mengirim seluruh refund tertunda payment lewat send lalu
+// memindahkannya ke JumlahRefund. Payment dikunci selama send berjalan
+// supaya refund yang sama tidak dikirim dua kali; bila send gagal, refund
+// tetap tertunda.
+func (r *paymentRepositoryImpl) SettleRefund(paymentID uint, send func(payment *models.Payment, jumlah int) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, paymentID).Error; err != nil {
+			return err
+		}
+		jumlah := payment.JumlahRefundTertunda
+		if jumlah <= 0 {
+			return nil
+		}
+		if err := send(&payment, jumlah); err != nil {
+			return err
+		}
+		return tx.Model(&models.Payment{}).Where("id = ?", payment.ID).
+			Updates(map[string]interface{}{
+				"jumlah_refund":          gorm.Expr("jumlah_refund + ?", jumlah),
+				"jumlah_refund_tertunda": gorm.Expr("jumlah_refund_tertunda - ?", jumlah),
+			}).Error
+	})
+}
@@ -1,6 +1,8 @@
 package product_repository
 
 import (
+	"time"
+
 	"test-rakamin/internal/models"
 
 	"gorm.io/gorm"
@@ -8,9 +10,15 @@ import (
 
 type ProductRepository interface {
 	Create(product *models.Product) error
-	FindAllWithFilter(nama, categoryID, tokoID, minHarga, maxHarga string) ([]models.Product, error)
+	FindAllWithFilter(filter ProductFilter) ([]models.Product, error)
 	FindByID(id uint) (*models.Product, error)
+	FindByIDs(ids []uint) ([]models.Product, error)
 	Update(product *models.Product) error
+	FindByTokoID(tokoID uint) ([]models.Product, error)
+	UpdateAlertStok(id uint, from, to string) (bool, error)
+	ReplaceAttributes(productID uint, attributes []models.ProductAttribute) error
+	PublishDue(now time.Time) (int64, error)
+	UnpublishDue(now time.Time) (int64, error)
 	Delete(id uint) error
 }
 
@@ -22,45 +30,153 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepositoryImpl{db: db}
 }
 
+// Create tidak menulis rating karena rating produk dihitung dari tabel
+// review.
 func (r *productRepositoryImpl) Create(product *models.Product) error {
-	return r.db.Create(product).Error
+	return r.db.Omit("RatingAvg", "RatingCount").Create(product).Error
+}
+
+// ProductFilter berisi filter daftar produk dari query string. Field
+// kosong berarti tidak difilter.
+type ProductFilter struct {
+	Nama       string
+	CategoryID string
+	// CategoryIDs, bila diisi, menggantikan CategoryID. Dipakai untuk
+	// memfilter kategori beserta seluruh turunannya.
+	CategoryIDs []uint
+	TokoID      string
+	Status      string
+	MinHarga    string
+	MaxHarga    string
+	MinRating   string
+	// Atribut memfilter produk dengan nilai atribut yang sama persis,
+	// misalnya {"brand": "Sony"}.
+	Atribut map[string]string
+	// Sort: rating, harga_asc, harga_desc atau terbaru. Selain itu produk
+	// diurutkan berdasarkan ID.
+	Sort string
 }
 
-func (r *productRepositoryImpl) FindAllWithFilter(nama, categoryID, tokoID, minHarga, maxHarga string) ([]models.Product, error) {
+func (r *productRepositoryImpl) FindAllWithFilter(filter ProductFilter) ([]models.Product, error) {
 	var products []models.Product
-	query := r.db.Model(&models.Product{}).Preload("Category").Preload("Toko").Preload("FotoProduk")
+	query := r.db.Model(&models.Product{}).Preload("Category").Preload("Toko").Preload("ProductPhoto").Preload("ProductVariant").Preload("PriceTier").Preload("Atribut")
 
-	if nama != "" {
-		query = query.Where("nama_produk LIKE ?", "%"+nama+"%")
+	if filter.Nama != "" {
+		query = query.Where("nama_product LIKE ?", "%"+filter.Nama+"%")
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("id_category IN ?", filter.CategoryIDs)
+	} else if filter.CategoryID != "" {
+		query = query.Where("id_category = ?", filter.CategoryID)
 	}
-	if categoryID != "" {
-		query = query.Where("category_id = ?", categoryID)
+	if filter.TokoID != "" {
+		query = query.Where("id_toko = ?", filter.TokoID)
 	}
-	if tokoID != "" {
-		query = query.Where("toko_id = ?", tokoID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
 	}
-	if minHarga != "" {
-		query = query.Where("harga_konsumen >= ?", minHarga)
+	if filter.MinHarga != "" {
+		query = query.Where("harga_konsumen >= ?", filter.MinHarga)
 	}
-	if maxHarga != "" {
-		query = query.Where("harga_konsumen <= ?", maxHarga)
+	if filter.MaxHarga != "" {
+		query = query.Where("harga_konsumen <= ?", filter.MaxHarga)
+	}
+	if filter.MinRating != "" {
+		query = query.Where("rating_avg >= ?", filter.MinRating)
+	}
+	for kode, nilai := range filter.Atribut {
+		query = query.Where("EXISTS (SELECT 1 FROM product_attributes pa WHERE pa.product_id = products.id AND pa.kode = ? AND pa.nilai = ? AND pa.deleted_at IS NULL)", kode, nilai)
 	}
 
-	err := query.Find(&products).Error
+	switch filter.Sort {
+	case "rating":
+		query = query.Order("rating_avg DESC").Order("rating_count DESC")
+	case "harga_asc":
+		query = query.Order("harga_konsumen ASC")
+	case "harga_desc":
+		query = query.Order("harga_konsumen DESC")
+	case "terbaru":
+		query = query.Order("created_at DESC")
+	}
+
+	err := query.Order("id").Find(&products).Error
 	return products, err
 }
 
 func (r *productRepositoryImpl) FindByID(id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.db.Preload("Category").Preload("Toko").Preload("FotoProduk").First(&product, id).Error
+	err := r.db.Preload("Category").Preload("Toko").Preload("ProductPhoto").Preload("ProductVariant").Preload("PriceTier").Preload("Atribut").First(&product, id).Error
 	if err == gorm.ErrRecordNotFound {
 		return nil, nil
 	}
 	return &product, err
 }
 
+func (r *productRepositoryImpl) FindByIDs(ids []uint) ([]models.Product, error) {
+	var products []models.Product
+	if len(ids) == 0 {
+		return products, nil
+	}
+	err := r.db.Preload("Category").Preload("Toko").Preload("ProductPhoto").Preload("ProductVariant").Preload("PriceTier").Preload("Atribut").
+		Where("id IN ?", ids).Find(&products).Error
+	return products, err
+}
+
+// Update tidak menulis kolom stok; perubahan stok harus lewat ledger
+// StockMovement supaya tidak menimpa pengurangan stok dari checkout.
+// AlertStok hanya diubah lewat UpdateAlertStok, rating dihitung dari
+// tabel review dan atribut diganti lewat ReplaceAttributes.
 func (r *productRepositoryImpl) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+	return r.db.Omit("Stok", "AlertStok", "RatingAvg", "RatingCount", "Atribut").Save(product).Error
+}
+
+func (r *productRepositoryImpl) FindByTokoID(tokoID uint) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Preload("Category").Preload("ProductPhoto").Preload("ProductVariant").Preload("Atribut").
+		Where("id_toko = ?", tokoID).Find(&products).Error
+	return products, err
+}
+
+// UpdateAlertStok mengganti AlertStok hanya bila nilainya masih from.
+// Mengembalikan false bila proses lain sudah mengubahnya lebih dulu.
+func (r *productRepositoryImpl) UpdateAlertStok(id uint, from, to string) (bool, error) {
+	res := r.db.Model(&models.Product{}).Where("id = ? AND alert_stok = ?", id, from).
+		UpdateColumn("alert_stok", to)
+	return res.RowsAffected > 0, res.Error
+}
+
+// ReplaceAttributes mengganti seluruh nilai atribut produk dalam satu
+// transaksi. Nilai lama dihapus permanen karena ada unique index per kode.
+func (r *productRepositoryImpl) ReplaceAttributes(productID uint, attributes []models.ProductAttribute) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("product_id = ?", productID).Delete(&models.ProductAttribute{}).Error; err != nil {
+			return err
+		}
+		if len(attributes) == 0 {
+			return nil
+		}
+		for i := range attributes {
+			attributes[i].ProductID = productID
+		}
+		return tx.Create(&attributes).Error
+	})
+}
+
+// PublishDue menerbitkan produk draft yang PublishAt-nya sudah lewat.
+func (r *productRepositoryImpl) PublishDue(now time.Time) (int64, error) {
+	res := r.db.Model(&models.Product{}).
+		Where("status = ? AND publish_at <= ?", models.ProductDraft, now).
+		Updates(map[string]interface{}{"status": models.ProductPublished, "publish_at": nil})
+	return res.RowsAffected, res.Error
+}
+
+// UnpublishDue mengarsipkan produk published yang UnpublishAt-nya sudah
+// lewat.
+func (r *productRepositoryImpl) UnpublishDue(now time.Time) (int64, error) {
+	res := r.db.Model(&models.Product{}).
+		Where("status = ? AND unpublish_at <= ?", models.ProductPublished, now).
+		Updates(map[string]interface{}{"status": models.ProductArchived, "unpublish_at": nil})
+	return res.RowsAffected, res.Error
 }
 
 func (r *productRepositoryImpl) Delete(id uint) error {
@@ -0,0 +1,55 @@
+package product_price_tier_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ProductPriceTierRepository interface {
+	Create(tier *models.ProductPriceTier) error
+	FindByProductID(productID uint) ([]models.ProductPriceTier, error)
+	FindByID(productID, id uint) (*models.ProductPriceTier, error)
+	FindByMinKuantitas(productID uint, minKuantitas int) (*models.ProductPriceTier, error)
+	Delete(id uint) error
+}
+
+type productPriceTierRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewProductPriceTierRepository(db *gorm.DB) ProductPriceTierRepository {
+	return &productPriceTierRepositoryImpl{db: db}
+}
+
+func (r *productPriceTierRepositoryImpl) Create(tier *models.ProductPriceTier) error {
+	return r.db.Create(tier).Error
+}
+
+func (r *productPriceTierRepositoryImpl) FindByProductID(productID uint) ([]models.ProductPriceTier, error) {
+	var tiers []models.ProductPriceTier
+	err := r.db.Where("product_id = ?", productID).Order("min_kuantitas").Find(&tiers).Error
+	return tiers, err
+}
+
+func (r *productPriceTierRepositoryImpl) FindByID(productID, id uint) (*models.ProductPriceTier, error) {
+	var tier models.ProductPriceTier
+	err := r.db.Where("id = ? AND product_id = ?", id, productID).First(&tier).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &tier, err
+}
+
+func (r *productPriceTierRepositoryImpl) FindByMinKuantitas(productID uint, minKuantitas int) (*models.ProductPriceTier, error) {
+	var tier models.ProductPriceTier
+	err := r.db.Where("product_id = ? AND min_kuantitas = ?", productID, minKuantitas).First(&tier).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &tier, err
+}
+
+func (r *productPriceTierRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&models.ProductPriceTier{}, id).Error
+}
@@ -0,0 +1,62 @@
+package product_variant_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ProductVariantRepository interface {
+	Create(variant *models.ProductVariant) error
+	FindByProductID(productID uint) ([]models.ProductVariant, error)
+	FindByID(productID, id uint) (*models.ProductVariant, error)
+	FindBySKU(productID uint, sku string) (*models.ProductVariant, error)
+	Update(variant *models.ProductVariant) error
+	Delete(id uint) error
+}
+
+type productVariantRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewProductVariantRepository(db *gorm.DB) ProductVariantRepository {
+	return &productVariantRepositoryImpl{db: db}
+}
+
+func (r *productVariantRepositoryImpl) Create(variant *models.ProductVariant) error {
+	return r.db.Create(variant).Error
+}
+
+func (r *productVariantRepositoryImpl) FindByProductID(productID uint) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	err := r.db.Where("product_id = ?", productID).Order("id").Find(&variants).Error
+	return variants, err
+}
+
+func (r *productVariantRepositoryImpl) FindByID(productID, id uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.Where("id = ? AND product_id = ?", id, productID).First(&variant).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &variant, err
+}
+
+func (r *productVariantRepositoryImpl) FindBySKU(productID uint, sku string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.Where("product_id = ? AND sku = ?", productID, sku).First(&variant).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &variant, err
+}
+
+// Update tidak menulis kolom stok; perubahan stok varian dicatat lewat
+// ledger StockMovement.
+func (r *productVariantRepositoryImpl) Update(variant *models.ProductVariant) error {
+	return r.db.Omit("Stok").Save(variant).Error
+}
+
+func (r *productVariantRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&models.ProductVariant{}, id).Error
+}
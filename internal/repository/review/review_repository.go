@@ -0,0 +1,136 @@
+package review_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository interface {
+	Create(review *models.Review) error
+	Update(review *models.Review) error
+	FindByID(id uint) (*models.Review, error)
+	FindByProductID(productID uint, rating int) ([]models.Review, error)
+	FindReviewableDetailTrx(userID, productID uint) ([]models.DetailTrx, error)
+	ReplacePhotos(reviewID uint, urls []string) ([]models.ReviewPhoto, error)
+	RefreshRatings(productID uint) error
+}
+
+type reviewRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepositoryImpl{db: db}
+}
+
+// preloadReviewer hanya memuat nama pembeli supaya data akun lain (email,
+// kata sandi) tidak ikut tampil di daftar review publik.
+func preloadReviewer(db *gorm.DB) *gorm.DB {
+	return db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "nama")
+	}).Preload("ReviewPhoto")
+}
+
+func (r *reviewRepositoryImpl) Create(review *models.Review) error {
+	return r.db.Omit("ReviewPhoto").Create(review).Error
+}
+
+func (r *reviewRepositoryImpl) Update(review *models.Review) error {
+	return r.db.Omit("User", "Product", "ReviewPhoto").Save(review).Error
+}
+
+func (r *reviewRepositoryImpl) FindByID(id uint) (*models.Review, error) {
+	var review models.Review
+	err := r.db.Scopes(preloadReviewer).First(&review, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &review, err
+}
+
+// FindByProductID mengembalikan review terbaru lebih dulu. rating 0 berarti
+// semua rating.
+func (r *reviewRepositoryImpl) FindByProductID(productID uint, rating int) ([]models.Review, error) {
+	var reviews []models.Review
+	query := r.db.Scopes(preloadReviewer).Where("product_id = ?", productID)
+	if rating > 0 {
+		query = query.Where("rating = ?", rating)
+	}
+	err := query.Order("id DESC").Find(&reviews).Error
+	return reviews, err
+}
+
+// FindReviewableDetailTrx mengembalikan pembelian produk oleh userID yang
+// sub-order tokonya sudah sampai dan belum direview. Trx lama tanpa
+// sub-order toko memakai status Trx-nya sendiri.
+func (r *reviewRepositoryImpl) FindReviewableDetailTrx(userID, productID uint) ([]models.DetailTrx, error) {
+	var details []models.DetailTrx
+	err := r.db.Joins("JOIN trxes ON trxes.id = detail_trxes.id_trx AND trxes.deleted_at IS NULL").
+		Joins("LEFT JOIN trx_tokos ON trx_tokos.id = detail_trxes.id_trx_toko AND trx_tokos.deleted_at IS NULL").
+		Where("trxes.id_user = ? AND detail_trxes.product_id = ?", userID, productID).
+		Where("(detail_trxes.id_trx_toko IS NOT NULL AND trx_tokos.status = ?) OR (detail_trxes.id_trx_toko IS NULL AND trxes.status = ?)",
+			models.TrxStatusDelivered, models.TrxStatusDelivered).
+		Where("NOT EXISTS (SELECT 1 FROM reviews WHERE reviews.id_detail_trx = detail_trxes.id)").
+		Order("detail_trxes.id").
+		Find(&details).Error
+	return details, err
+}
+
+func (r *reviewRepositoryImpl) ReplacePhotos(reviewID uint, urls []string) ([]models.ReviewPhoto, error) {
+	photos := make([]models.ReviewPhoto, 0, len(urls))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ?", reviewID).Delete(&models.ReviewPhoto{}).Error; err != nil {
+			return err
+		}
+		for _, url := range urls {
+			photos = append(photos, models.ReviewPhoto{ReviewID: reviewID, URL: url})
+		}
+		if len(photos) == 0 {
+			return nil
+		}
+		return tx.Create(&photos).Error
+	})
+	return photos, err
+}
+
+// RefreshRatings menghitung ulang rata-rata dan jumlah rating produk
+// beserta tokonya dari tabel review, sehingga hasilnya tetap benar walau
+// ada review yang dibuat bersamaan.
+func (r *reviewRepositoryImpl) RefreshRatings(productID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Select("id", "id_toko").First(&product, productID).Error; err != nil {
+			return err
+		}
+
+		var productStats struct {
+			Avg   float64
+			Count int
+		}
+		if err := tx.Model(&models.Review{}).
+			Select("COALESCE(AVG(rating), 0) AS avg, COUNT(*) AS count").
+			Where("product_id = ?", productID).
+			Scan(&productStats).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Product{}).Where("id = ?", productID).
+			UpdateColumns(map[string]interface{}{"rating_avg": productStats.Avg, "rating_count": productStats.Count}).Error; err != nil {
+			return err
+		}
+
+		var tokoStats struct {
+			Avg   float64
+			Count int
+		}
+		if err := tx.Model(&models.Review{}).
+			Select("COALESCE(AVG(reviews.rating), 0) AS avg, COUNT(*) AS count").
+			Joins("JOIN products ON products.id = reviews.product_id AND products.deleted_at IS NULL").
+			Where("products.id_toko = ?", product.IDToko).
+			Scan(&tokoStats).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Toko{}).Where("id = ?", product.IDToko).
+			UpdateColumns(map[string]interface{}{"rating_avg": tokoStats.Avg, "rating_count": tokoStats.Count}).Error
+	})
+}
@@ -0,0 +1,108 @@
+package shipment_repository
+
+import (
+	"errors"
+
+	"test-rakamin/internal/models"
+	trx_repository "test-rakamin/internal/repository/trx"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDelivered dikembalikan AddEvent bila pengiriman sudah sampai.
+	ErrDelivered = errors.New("shipment has already been delivered")
+	// ErrDuplicateEvent dikembalikan AddEvent bila EventID sudah tercatat.
+	ErrDuplicateEvent = errors.New("shipment event has already been recorded")
+)
+
+type ShipmentRepository interface {
+	FindByTrxTokoID(trxTokoID uint) (*models.Shipment, error)
+	FindByResi(kurir, noResi string) (*models.Shipment, error)
+	AddEvent(shipment *models.Shipment, event *models.ShipmentEvent) error
+}
+
+type shipmentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepository(db *gorm.DB) ShipmentRepository {
+	return &shipmentRepositoryImpl{db: db}
+}
+
+func (r *shipmentRepositoryImpl) FindByTrxTokoID(trxTokoID uint) (*models.Shipment, error) {
+	var shipment models.Shipment
+	err := r.db.Preload("Events", trx_repository.ShipmentEventOrder).Where("id_trx_toko = ?", trxTokoID).First(&shipment).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &shipment, err
+}
+
+// FindByResi mengembalikan shipment terbaru dengan kurir dan nomor resi
+// tersebut.
+func (r *shipmentRepositoryImpl) FindByResi(kurir, noResi string) (*models.Shipment, error) {
+	var shipment models.Shipment
+	err := r.db.Preload("TrxToko.Trx").Preload("Events", trx_repository.ShipmentEventOrder).Where("kurir = ? AND no_resi = ?", kurir, noResi).
+		Order("id DESC").First(&shipment).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &shipment, err
+}
+
+// AddEvent mencatat event dan mengubah status shipment dalam satu database
+// transaction. Event delivered juga menandai sub-order sebagai delivered,
+// lalu Trx-nya bila tidak ada lagi sub-order yang belum selesai.
+func (r *shipmentRepositoryImpl) AddEvent(shipment *models.Shipment, event *models.ShipmentEvent) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Shipment dikunci supaya dua event delivered bersamaan tidak
+		// sama-sama diproses.
+		var current models.Shipment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, shipment.ID).Error; err != nil {
+			return err
+		}
+		if current.Status == models.ShipmentDelivered {
+			// Kurir bisa mengirim ulang event yang sudah tercatat.
+			if event.EventID != nil && tx.Where("id_shipment = ? AND event_id = ?", shipment.ID, *event.EventID).
+				Take(&models.ShipmentEvent{}).Error == nil {
+				return ErrDuplicateEvent
+			}
+			return ErrDelivered
+		}
+
+		event.IDShipment = shipment.ID
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrDuplicateEvent
+		}
+
+		updates := map[string]interface{}{"status": event.Status}
+		if event.Status == models.ShipmentDelivered {
+			updates["delivered_at"] = event.Waktu
+		}
+		if err := tx.Model(&models.Shipment{}).Where("id = ?", shipment.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		shipment.Status = event.Status
+		if event.Status != models.ShipmentDelivered {
+			return nil
+		}
+		shipment.DeliveredAt = &event.Waktu
+		return markDeliveredTx(tx, shipment)
+	})
+}
+
+func markDeliveredTx(tx *gorm.DB, shipment *models.Shipment) error {
+	err := tx.Model(&models.TrxToko{}).
+		Where("id = ? AND status = ?", shipment.IDTrxToko, models.TrxStatusShipped).
+		Update("status", models.TrxStatusDelivered).Error
+	if err != nil {
+		return err
+	}
+	return trx_repository.CloseTrxTx(tx, shipment.IDTrx)
+}
@@ -0,0 +1,191 @@
+package stock_movement_repository
+
+import (
+	"errors"
+
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("stock is insufficient")
+
+type StockMovementRepository interface {
+	Apply(movement *models.StockMovement) error
+	FindByProductID(productID uint) ([]models.StockMovement, error)
+	Reconcile(productID uint) (*ReconcileResult, error)
+}
+
+// ReconcileResult berisi stok yang tersimpan sebelum dan sesudah disamakan
+// dengan total ledger. Key map varian adalah ID varian.
+type ReconcileResult struct {
+	ProductID        uint
+	StokSebelum      int
+	StokSesudah      int
+	VariantSebelum   map[uint]int
+	VariantSesudah   map[uint]int
+	SaldoAwalDicatat bool
+}
+
+type stockMovementRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewStockMovementRepository(db *gorm.DB) StockMovementRepository {
+	return &stockMovementRepositoryImpl{db: db}
+}
+
+func (r *stockMovementRepositoryImpl) Apply(movement *models.StockMovement) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return ApplyTx(tx, movement)
+	})
+}
+
+// ApplyTx mengubah stok sesuai movement.Jumlah lalu mencatat movement di
+// dalam transaction tx. Stok varian selalu ikut mengubah stok produknya
+// karena Product.Stok adalah total stok varian. Mengembalikan
+// ErrInsufficientStock bila stok akan menjadi negatif.
+func ApplyTx(tx *gorm.DB, movement *models.StockMovement) error {
+	if movement.VariantID != nil {
+		res := tx.Model(&models.ProductVariant{}).
+			Where("id = ? AND product_id = ? AND stok + ? >= 0", *movement.VariantID, movement.ProductID, movement.Jumlah).
+			UpdateColumn("stok", gorm.Expr("stok + ?", movement.Jumlah))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+		if err := tx.Model(&models.Product{}).Where("id = ?", movement.ProductID).
+			UpdateColumn("stok", gorm.Expr("stok + ?", movement.Jumlah)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ProductVariant{}).Select("stok").
+			Where("id = ?", *movement.VariantID).Scan(&movement.StokAkhir).Error; err != nil {
+			return err
+		}
+	} else {
+		res := tx.Model(&models.Product{}).
+			Where("id = ? AND stok + ? >= 0", movement.ProductID, movement.Jumlah).
+			UpdateColumn("stok", gorm.Expr("stok + ?", movement.Jumlah))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+		if err := tx.Model(&models.Product{}).Select("stok").
+			Where("id = ?", movement.ProductID).Scan(&movement.StokAkhir).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Create(movement).Error
+}
+
+func (r *stockMovementRepositoryImpl) FindByProductID(productID uint) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+	err := r.db.Where("product_id = ?", productID).Order("id DESC").Find(&movements).Error
+	return movements, err
+}
+
+// Reconcile menyamakan stok produk dan variannya dengan total ledger.
+// Produk yang belum punya ledger sama sekali (data lama) dicatat saldo
+// awalnya sebagai movement import terlebih dulu.
+func (r *stockMovementRepositoryImpl) Reconcile(productID uint) (*ReconcileResult, error) {
+	result := &ReconcileResult{
+		ProductID:      productID,
+		VariantSebelum: map[uint]int{},
+		VariantSesudah: map[uint]int{},
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error; err != nil {
+			return err
+		}
+		var variants []models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("product_id = ?", productID).Find(&variants).Error; err != nil {
+			return err
+		}
+
+		result.StokSebelum = product.Stok
+		for _, v := range variants {
+			result.VariantSebelum[v.ID] = v.Stok
+		}
+
+		var count int64
+		if err := tx.Model(&models.StockMovement{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			result.SaldoAwalDicatat = true
+			if err := recordOpeningBalance(tx, &product, variants); err != nil {
+				return err
+			}
+		}
+
+		var rows []struct {
+			VariantID *uint
+			Total     int
+		}
+		if err := tx.Model(&models.StockMovement{}).
+			Select("variant_id, SUM(jumlah) AS total").
+			Where("product_id = ?", productID).
+			Group("variant_id").Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		total := 0
+		for _, row := range rows {
+			total += row.Total
+			if row.VariantID != nil {
+				result.VariantSesudah[*row.VariantID] = row.Total
+			}
+		}
+		result.StokSesudah = total
+
+		for _, v := range variants {
+			stok := result.VariantSesudah[v.ID]
+			if err := tx.Model(&models.ProductVariant{}).Where("id = ?", v.ID).
+				UpdateColumn("stok", stok).Error; err != nil {
+				return err
+			}
+			result.VariantSesudah[v.ID] = stok
+		}
+		return tx.Model(&models.Product{}).Where("id = ?", productID).
+			UpdateColumn("stok", total).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func recordOpeningBalance(tx *gorm.DB, product *models.Product, variants []models.ProductVariant) error {
+	if len(variants) == 0 {
+		return tx.Create(&models.StockMovement{
+			ProductID: product.ID,
+			Tipe:      models.StockMovementImport,
+			Jumlah:    product.Stok,
+			StokAkhir: product.Stok,
+			Alasan:    "saldo awal",
+		}).Error
+	}
+	for _, v := range variants {
+		variantID := v.ID
+		if err := tx.Create(&models.StockMovement{
+			ProductID: product.ID,
+			VariantID: &variantID,
+			Tipe:      models.StockMovementImport,
+			Jumlah:    v.Stok,
+			StokAkhir: v.Stok,
+			Alasan:    "saldo awal",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package stock_reservation_repository
+
+import (
+	"time"
+
+	"test-rakamin/internal/models"
+	stock_movement_repository "test-rakamin/internal/repository/stock_movement"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockReservationRepository interface {
+	FindByTrxID(trxID uint) ([]models.StockReservation, error)
+	ReservedTotals(productIDs []uint) (*ReservedTotals, error)
+}
+
+// ReservedTotals berisi jumlah unit yang sedang direservasi. Product sudah
+// termasuk reservasi varian-variannya, sama seperti Product.Stok.
+type ReservedTotals struct {
+	Product map[uint]int
+	Variant map[uint]int
+}
+
+type stockReservationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewStockReservationRepository(db *gorm.DB) StockReservationRepository {
+	return &stockReservationRepositoryImpl{db: db}
+}
+
+// activeScope memilih reservasi yang masih menahan stok. Reservasi yang
+// sudah lewat ExpiresAt tidak dihitung walaupun reaper belum sempat
+// melepasnya.
+func activeScope(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? AND expires_at > ?", models.ReservationActive, now)
+	}
+}
+
+// ReserveTx membuat reservasi di dalam transaction tx. Baris varian atau
+// produk dikunci dulu supaya dua checkout bersamaan tidak bisa
+// mereservasi stok yang sama. Mengembalikan
+// stock_movement_repository.ErrInsufficientStock bila stok tersedia kurang.
+func ReserveTx(tx *gorm.DB, reservation *models.StockReservation) error {
+	now := time.Now()
+	var stok int
+	reserved := tx.Model(&models.StockReservation{}).Scopes(activeScope(now)).
+		Select("COALESCE(SUM(kuantitas), 0)")
+
+	if reservation.VariantID != nil {
+		var variant models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND product_id = ?", *reservation.VariantID, reservation.ProductID).
+			First(&variant).Error; err != nil {
+			return err
+		}
+		stok = variant.Stok
+		reserved = reserved.Where("variant_id = ?", variant.ID)
+	} else {
+		var product models.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&product, reservation.ProductID).Error; err != nil {
+			return err
+		}
+		stok = product.Stok
+		reserved = reserved.Where("product_id = ?", product.ID)
+	}
+
+	var total int
+	if err := reserved.Scan(&total).Error; err != nil {
+		return err
+	}
+	if stok-total < reservation.Kuantitas {
+		return stock_movement_repository.ErrInsufficientStock
+	}
+
+	reservation.Status = models.ReservationActive
+	return tx.Create(reservation).Error
+}
+
+// ReleaseTx melepas semua reservasi aktif milik transaksi trxID sehingga
+// stoknya kembali tersedia.
+func ReleaseTx(tx *gorm.DB, trxID uint) error {
+	return tx.Model(&models.StockReservation{}).
+		Where("id_trx = ? AND status = ?", trxID, models.ReservationActive).
+		Update("status", models.ReservationReleased).Error
+}
+
+// ConsumeTx mengubah reservasi aktif milik transaksi trxID menjadi
+// pengurangan stok di ledger. Dipanggil saat transaksi dibayar.
+func ConsumeTx(tx *gorm.DB, trx *models.Trx) error {
+	var reservations []models.StockReservation
+	if err := tx.Where("id_trx = ? AND status = ?", trx.ID, models.ReservationActive).
+		Find(&reservations).Error; err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		err := stock_movement_repository.ApplyTx(tx, &models.StockMovement{
+			ProductID: reservation.ProductID,
+			VariantID: reservation.VariantID,
+			Tipe:      models.StockMovementSale,
+			Jumlah:    -reservation.Kuantitas,
+			Alasan:    "pembayaran " + trx.KodeInvoice,
+			IDUser:    trx.IDUser,
+			IDTrx:     &trx.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.StockReservation{}).Where("id = ?", reservation.ID).
+			Update("status", models.ReservationConsumed).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *stockReservationRepositoryImpl) FindByTrxID(trxID uint) ([]models.StockReservation, error) {
+	var reservations []models.StockReservation
+	err := r.db.Where("id_trx = ?", trxID).Find(&reservations).Error
+	return reservations, err
+}
+
+func (r *stockReservationRepositoryImpl) ReservedTotals(productIDs []uint) (*ReservedTotals, error) {
+	totals := &ReservedTotals{Product: map[uint]int{}, Variant: map[uint]int{}}
+	if len(productIDs) == 0 {
+		return totals, nil
+	}
+
+	var rows []struct {
+		ProductID uint
+		VariantID *uint
+		Total     int
+	}
+	err := r.db.Model(&models.StockReservation{}).Scopes(activeScope(time.Now())).
+		Select("product_id, variant_id, SUM(kuantitas) AS total").
+		Where("product_id IN ?", productIDs).
+		Group("product_id, variant_id").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		totals.Product[row.ProductID] += row.Total
+		if row.VariantID != nil {
+			totals.Variant[*row.VariantID] += row.Total
+		}
+	}
+	return totals, nil
+}
@@ -0,0 +1,146 @@
+package stored_file_repository
+
+import (
+	"time"
+
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StoredFileRepository interface {
+	Create(file *models.StoredFile) error
+	FindAll() ([]models.StoredFile, error)
+	FindByHash(hash string) (*models.StoredFile, error)
+	IncrementRef(key string) (bool, error)
+	DecrementRef(key string) error
+	UpdateRefCount(id uint, refCount int) error
+	CountReferences(key string) (int64, error)
+	ReferencedKeys() ([]string, error)
+	DeleteUnreferenced(id uint, cutoff time.Time, removeObjects func(key string) error) (bool, error)
+}
+
+type storedFileRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewStoredFileRepository(db *gorm.DB) StoredFileRepository {
+	return &storedFileRepositoryImpl{db: db}
+}
+
+func (r *storedFileRepositoryImpl) Create(file *models.StoredFile) error {
+	return r.db.Create(file).Error
+}
+
+func (r *storedFileRepositoryImpl) FindAll() ([]models.StoredFile, error) {
+	var files []models.StoredFile
+	err := r.db.Find(&files).Error
+	return files, err
+}
+
+func (r *storedFileRepositoryImpl) FindByHash(hash string) (*models.StoredFile, error) {
+	var file models.StoredFile
+	err := r.db.Where("hash = ?", hash).First(&file).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &file, err
+}
+
+// IncrementRef juga memperbarui updated_at agar file yang baru dipakai ulang
+// tidak ikut dihapus garbage collector sebelum referensinya tersimpan.
+// Mengembalikan false bila barisnya sudah dihapus garbage collector.
+func (r *storedFileRepositoryImpl) IncrementRef(key string) (bool, error) {
+	res := r.db.Model(&models.StoredFile{}).Where("key = ?", key).
+		UpdateColumns(map[string]interface{}{
+			"ref_count":  gorm.Expr("ref_count + 1"),
+			"updated_at": time.Now(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *storedFileRepositoryImpl) DecrementRef(key string) error {
+	return r.db.Model(&models.StoredFile{}).Where("key = ? AND ref_count > 0", key).
+		UpdateColumn("ref_count", gorm.Expr("ref_count - 1")).Error
+}
+
+func (r *storedFileRepositoryImpl) UpdateRefCount(id uint, refCount int) error {
+	return r.db.Model(&models.StoredFile{}).Where("id = ?", id).
+		UpdateColumn("ref_count", refCount).Error
+}
+
+// referenceColumns adalah kolom yang menyimpan key file upload. Baris yang
+// sudah dihapus tidak dihitung.
+var referenceColumns = []struct {
+	model  interface{}
+	column string
+}{
+	{&models.ProductPhoto{}, "url"},
+	{&models.ProductVariant{}, "url_foto"},
+	{&models.Toko{}, "url_foto_toko"},
+	{&models.ReviewPhoto{}, "url"},
+}
+
+// CountReferences menghitung baris yang masih memakai key.
+func (r *storedFileRepositoryImpl) CountReferences(key string) (int64, error) {
+	return countReferences(r.db, key)
+}
+
+func countReferences(db *gorm.DB, key string) (int64, error) {
+	var total int64
+	for _, ref := range referenceColumns {
+		var count int64
+		if err := db.Model(ref.model).Where(ref.column+" = ?", key).Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// ReferencedKeys mengembalikan semua key yang masih dipakai, termasuk file
+// lama yang tidak punya baris StoredFile.
+func (r *storedFileRepositoryImpl) ReferencedKeys() ([]string, error) {
+	var keys []string
+	for _, ref := range referenceColumns {
+		var values []string
+		err := r.db.Model(ref.model).Where(ref.column+" <> ''").
+			Distinct().Pluck(ref.column, &values).Error
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, values...)
+	}
+	return keys, nil
+}
+
+// DeleteUnreferenced mengunci baris file id, memastikan file tidak dipakai
+// dan tidak diubah setelah cutoff, lalu menghapus object-nya lewat
+// removeObjects dan barisnya dalam satu database transaction. IncrementRef
+// yang berjalan bersamaan menunggu kunci ini lalu mendapati barisnya sudah
+// hilang, sehingga SaveImage menyimpan ulang file-nya.
+func (r *storedFileRepositoryImpl) DeleteUnreferenced(id uint, cutoff time.Time, removeObjects func(key string) error) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var file models.StoredFile
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND updated_at <= ?", id, cutoff).First(&file).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		refs, err := countReferences(tx, file.Key)
+		if err != nil || refs > 0 {
+			return err
+		}
+		if err := removeObjects(file.Key); err != nil {
+			return err
+		}
+		deleted = true
+		return tx.Unscoped().Delete(&models.StoredFile{}, file.ID).Error
+	})
+	return deleted && err == nil, err
+}
@@ -51,8 +51,9 @@ func (r *tokoRepositoryImpl) FindByUserID(userID uint) (*models.Toko, error) {
 	return &toko, err
 }
 
+// Update tidak menulis rating karena rating toko dihitung dari tabel review.
 func (r *tokoRepositoryImpl) Update(toko *models.Toko) error {
-	return r.db.Save(toko).Error
+	return r.db.Omit("RatingAvg", "RatingCount").Save(toko).Error
 }
 
 func (r *tokoRepositoryImpl) Delete(id uint) error {
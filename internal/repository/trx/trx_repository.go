@@ -1,7 +1,17 @@
 package trx_repository
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
 	"test-rakamin/internal/models"
+	discount_repository "test-rakamin/internal/repository/discount"
+	payment_repository "test-rakamin/internal/repository/payment"
+	stock_movement_repository "test-rakamin/internal/repository/stock_movement"
+	stock_reservation_repository "test-rakamin/internal/repository/stock_reservation"
+	voucher_repository "test-rakamin/internal/repository/voucher"
 
 	"gorm.io/gorm"
 )
@@ -10,6 +20,15 @@ type TrxRepository interface {
 	Create(trx *models.Trx) error
 	FindByUserID(userID uint) ([]models.Trx, error)
 	FindByIDAndUserID(id uint, userID uint) (*models.Trx, error)
+	CancelExpired(now time.Time) ([]models.Trx, error)
+	CancelPending(trx *models.Trx) (bool, error)
+	FindSubOrders(tokoID uint, filter SubOrderFilter) ([]models.TrxToko, error)
+	FindSubOrder(id, tokoID uint) (*models.TrxToko, error)
+	UpdateSubOrder(sub *models.TrxToko, fromStatus ...string) (bool, error)
+	ShipSubOrder(sub *models.TrxToko, shipment *models.Shipment, fromStatus ...string) (bool, error)
+	RejectSubOrder(sub *models.TrxToko, userID uint, fromStatus ...string) (bool, error)
+	CollectCOD(sub *models.TrxToko) (bool, error)
+	Settlement(tokoID uint, filter SubOrderFilter) (*models.SettlementReport, error)
 }
 
 type trxRepositoryImpl struct {
@@ -20,21 +39,374 @@ func NewTrxRepository(db *gorm.DB) TrxRepository {
 	return &trxRepositoryImpl{db: db}
 }
 
+// Create menyimpan transaksi beserta sub-order toko, detail dan redemption
+// vouchernya, mereservasi stok dan memakai kuota diskon setiap item serta
+// kuota voucher dalam satu database transaction. Stok baru dipotong di
+// ledger ketika transaksi dibayar, kecuali untuk transaksi COD yang
+// langsung berstatus processing.
 func (r *trxRepositoryImpl) Create(trx *models.Trx) error {
-	return r.db.Create(trx).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Detail disimpan sesudah sub-order karena butuh ID keduanya.
+		if err := tx.Omit("DetailTrx", "TrxToko").Create(trx).Error; err != nil {
+			return err
+		}
+		for i := range trx.TrxToko {
+			sub := &trx.TrxToko[i]
+			sub.IDTrx = trx.ID
+			if err := tx.Omit("DetailTrx").Create(sub).Error; err != nil {
+				return err
+			}
+			for j := range trx.DetailTrx {
+				if trx.DetailTrx[j].IDToko == sub.IDToko {
+					trx.DetailTrx[j].IDTrxToko = &sub.ID
+				}
+			}
+		}
+		for i := range trx.DetailTrx {
+			trx.DetailTrx[i].IDTrx = trx.ID
+		}
+		if len(trx.DetailTrx) > 0 {
+			if err := tx.Create(&trx.DetailTrx).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		// Transaksi tanpa batas bayar (COD) langsung memakai reservasinya,
+		// jadi reservasinya cukup berlaku selama database transaction ini.
+		reservedUntil := now.Add(time.Minute)
+		if trx.ExpiresAt != nil {
+			reservedUntil = *trx.ExpiresAt
+		}
+		if trx.VoucherRedemption != nil {
+			err := voucher_repository.ClaimTx(tx, trx.VoucherRedemption, now)
+			if errors.Is(err, voucher_repository.ErrVoucherUnavailable) {
+				return fmt.Errorf("voucher %s is no longer available", trx.KodeVoucher)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, detail := range trx.DetailTrx {
+			if detail.IDDiscount != nil {
+				err := discount_repository.ClaimTx(tx, *detail.IDDiscount, detail.Kuantitas, now)
+				if errors.Is(err, discount_repository.ErrQuotaExhausted) {
+					return fmt.Errorf("discount for product %d is no longer available", detail.ProductID)
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			err := stock_reservation_repository.ReserveTx(tx, &models.StockReservation{
+				IDTrx:     trx.ID,
+				ProductID: detail.ProductID,
+				VariantID: detail.VariantID,
+				Kuantitas: detail.Kuantitas,
+				ExpiresAt: reservedUntil,
+			})
+			if errors.Is(err, stock_movement_repository.ErrInsufficientStock) {
+				return fmt.Errorf("stock for product %d is insufficient", detail.ProductID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if trx.Status == models.TrxStatusProcessing {
+			return stock_reservation_repository.ConsumeTx(tx, trx)
+		}
+		return nil
+	})
+}
+
+// CancelExpired membatalkan transaksi pending_payment yang sudah melewati
+// ExpiresAt lalu melepas reservasi, kuota diskon dan vouchernya. Status
+// diubah secara bersyarat sehingga transaksi yang dibayar bersamaan tidak
+// ikut dibatalkan.
+func (r *trxRepositoryImpl) CancelExpired(now time.Time) ([]models.Trx, error) {
+	var expired []models.Trx
+	err := r.db.Preload("DetailTrx").
+		Where("status = ? AND expires_at <= ?", models.TrxStatusPendingPayment, now).
+		Find(&expired).Error
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled := make([]models.Trx, 0, len(expired))
+	for _, trx := range expired {
+		changed, err := r.CancelPending(&trx)
+		if err != nil {
+			return cancelled, err
+		}
+		if changed {
+			cancelled = append(cancelled, trx)
+		}
+	}
+	return cancelled, nil
+}
+
+// CancelPending membatalkan trx beserta DetailTrx-nya seperti
+// CancelExpired tanpa menunggu ExpiresAt. Hasil false berarti trx sudah
+// tidak pending_payment.
+func (r *trxRepositoryImpl) CancelPending(trx *models.Trx) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Trx{}).
+			Where("id = ? AND status = ?", trx.ID, models.TrxStatusPendingPayment).
+			Update("status", models.TrxStatusCancelled)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		changed = true
+		err := tx.Model(&models.TrxToko{}).
+			Where("id_trx = ?", trx.ID).
+			Update("status", models.TrxStatusCancelled).Error
+		if err != nil {
+			return err
+		}
+		for _, detail := range trx.DetailTrx {
+			if detail.IDDiscount == nil {
+				continue
+			}
+			if err := discount_repository.ReleaseTx(tx, *detail.IDDiscount, detail.Kuantitas); err != nil {
+				return err
+			}
+		}
+		if err := voucher_repository.ReleaseTx(tx, trx.ID); err != nil {
+			return err
+		}
+		return stock_reservation_repository.ReleaseTx(tx, trx.ID)
+	})
+	if err != nil {
+		return false, err
+	}
+	if changed {
+		trx.Status = models.TrxStatusCancelled
+	}
+	return changed, nil
+}
+
+// ShipmentEventOrder mengurutkan timeline pengiriman dari event paling
+// awal. Dipakai sebagai kondisi Preload("...Events").
+func ShipmentEventOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("waktu").Order("id")
 }
 
 func (r *trxRepositoryImpl) FindByUserID(userID uint) ([]models.Trx, error) {
 	var trxList []models.Trx
-	err := r.db.Preload("DetailTrx").Where("id_user = ?", userID).Find(&trxList).Error
+	err := r.db.Preload("DetailTrx").Preload("TrxToko.Shipment.Events", ShipmentEventOrder).Preload("VoucherRedemption").Preload("Payment").Where("id_user = ?", userID).Find(&trxList).Error
 	return trxList, err
 }
 
 func (r *trxRepositoryImpl) FindByIDAndUserID(id uint, userID uint) (*models.Trx, error) {
 	var trx models.Trx
-	err := r.db.Preload("DetailTrx").Where("id = ? AND id_user = ?", id, userID).First(&trx).Error
+	err := r.db.Preload("DetailTrx").Preload("TrxToko.Shipment.Events", ShipmentEventOrder).Preload("VoucherRedemption").Preload("Payment").Where("id = ? AND id_user = ?", id, userID).First(&trx).Error
 	if err == gorm.ErrRecordNotFound {
 		return nil, nil
 	}
 	return &trx, err
 }
+
+// SubOrderFilter berisi filter daftar pesanan toko. Field kosong berarti
+// tidak difilter; To bersifat eksklusif.
+type SubOrderFilter struct {
+	Status string
+	From   *time.Time
+	To     *time.Time
+}
+
+func (r *trxRepositoryImpl) FindSubOrders(tokoID uint, filter SubOrderFilter) ([]models.TrxToko, error) {
+	var subOrders []models.TrxToko
+	query := r.db.Preload("DetailTrx").Preload("Trx.Alamat").Preload("Shipment.Events", ShipmentEventOrder).Where("id_toko = ?", tokoID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	err := query.Order("created_at DESC").Order("id DESC").Find(&subOrders).Error
+	return subOrders, err
+}
+
+func (r *trxRepositoryImpl) FindSubOrder(id, tokoID uint) (*models.TrxToko, error) {
+	var sub models.TrxToko
+	err := r.db.Preload("DetailTrx").Preload("Trx.Alamat").Preload("Shipment.Events", ShipmentEventOrder).Where("id = ? AND id_toko = ?", id, tokoID).First(&sub).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &sub, err
+}
+
+// UpdateSubOrder menyimpan status, resi dan alasan tolak sub-order hanya
+// bila statusnya di database masih salah satu fromStatus. Hasil false
+// berarti status sudah diubah proses lain.
+func (r *trxRepositoryImpl) UpdateSubOrder(sub *models.TrxToko, fromStatus ...string) (bool, error) {
+	res := r.db.Model(&models.TrxToko{}).
+		Where("id = ? AND status IN ?", sub.ID, fromStatus).
+		Updates(map[string]interface{}{
+			"status":       sub.Status,
+			"no_resi":      sub.NoResi,
+			"shipped_at":   sub.ShippedAt,
+			"alasan_tolak": sub.AlasanTolak,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ShipSubOrder seperti UpdateSubOrder lalu menyimpan shipment sub-order
+// dalam satu database transaction.
+func (r *trxRepositoryImpl) ShipSubOrder(sub *models.TrxToko, shipment *models.Shipment, fromStatus ...string) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.TrxToko{}).
+			Where("id = ? AND status IN ?", sub.ID, fromStatus).
+			Updates(map[string]interface{}{"status": sub.Status, "no_resi": sub.NoResi, "shipped_at": sub.ShippedAt})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		changed = true
+		return tx.Create(shipment).Error
+	})
+	return changed, err
+}
+
+// RejectSubOrder seperti UpdateSubOrder lalu, dalam satu database
+// transaction, mengembalikan stok setiap item sub-order ke ledger karena
+// stok sudah dipotong saat transaksi dibayar, mencatat total sub-order
+// sebagai refund tertunda, dan menutup Trx bila semua sub-order-nya sudah
+// selesai.
+func (r *trxRepositoryImpl) RejectSubOrder(sub *models.TrxToko, userID uint, fromStatus ...string) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.TrxToko{}).
+			Where("id = ? AND status IN ?", sub.ID, fromStatus).
+			Updates(map[string]interface{}{"status": sub.Status, "alasan_tolak": sub.AlasanTolak})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		changed = true
+		for _, detail := range sub.DetailTrx {
+			err := stock_movement_repository.ApplyTx(tx, &models.StockMovement{
+				ProductID: detail.ProductID,
+				VariantID: detail.VariantID,
+				Tipe:      models.StockMovementCancellation,
+				Jumlah:    detail.Kuantitas,
+				Alasan:    "pesanan ditolak " + sub.KodeInvoice,
+				IDUser:    userID,
+				IDTrx:     &sub.IDTrx,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := payment_repository.AddPendingRefundTx(tx, sub.IDTrx, sub.HargaTotal); err != nil {
+			return err
+		}
+		return CloseTrxTx(tx, sub.IDTrx)
+	})
+	return changed, err
+}
+
+// finishedSubOrderStatuses adalah status sub-order yang tidak akan berubah
+// lagi.
+var finishedSubOrderStatuses = []string{models.TrxStatusDelivered, models.TrxStatusRejected, models.TrxStatusCancelled}
+
+// CloseTrxTx dipanggil di dalam transaction tx setelah sub-order ditolak
+// atau sampai. Bila semua sub-order sudah selesai, Trx menjadi delivered
+// kalau ada yang sampai; kalau semua ditolak Trx menjadi cancelled dan
+// kuota diskon serta vouchernya dikembalikan.
+func CloseTrxTx(tx *gorm.DB, trxID uint) error {
+	var subOrders []models.TrxToko
+	if err := tx.Where("id_trx = ?", trxID).Find(&subOrders).Error; err != nil {
+		return err
+	}
+	delivered := false
+	for _, sub := range subOrders {
+		if !slices.Contains(finishedSubOrderStatuses, sub.Status) {
+			return nil
+		}
+		delivered = delivered || sub.Status == models.TrxStatusDelivered
+	}
+
+	openStatuses := []string{models.TrxStatusPaid, models.TrxStatusProcessing}
+	if delivered {
+		return tx.Model(&models.Trx{}).
+			Where("id = ? AND status IN ?", trxID, openStatuses).
+			Update("status", models.TrxStatusDelivered).Error
+	}
+	res := tx.Model(&models.Trx{}).
+		Where("id = ? AND status IN ?", trxID, openStatuses).
+		Update("status", models.TrxStatusCancelled)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+	var details []models.DetailTrx
+	if err := tx.Where("id_trx = ? AND id_discount IS NOT NULL", trxID).Find(&details).Error; err != nil {
+		return err
+	}
+	for _, detail := range details {
+		if err := discount_repository.ReleaseTx(tx, *detail.IDDiscount, detail.Kuantitas); err != nil {
+			return err
+		}
+	}
+	return voucher_repository.ReleaseTx(tx, trxID)
+}
+
+// CollectCOD mencatat uang pesanan COD sudah diterima seller. Hanya
+// berlaku untuk sub-order yang sudah dikirim dan belum dicatat; hasil
+// false berarti syarat tersebut tidak terpenuhi.
+func (r *trxRepositoryImpl) CollectCOD(sub *models.TrxToko) (bool, error) {
+	res := r.db.Model(&models.TrxToko{}).
+		Where("id = ? AND cod_collected_at IS NULL AND status IN ?", sub.ID, []string{models.TrxStatusShipped, models.TrxStatusDelivered}).
+		Update("cod_collected_at", sub.CODCollectedAt)
+	return res.RowsAffected > 0, res.Error
+}
+
+// settlementStatuses adalah status sub-order yang dihitung di laporan
+// settlement.
+var settlementStatuses = []string{models.TrxStatusPaid, models.TrxStatusProcessing, models.TrxStatusShipped, models.TrxStatusDelivered}
+
+// Settlement merangkum sub-order toko sesuai filter. Filter.Status
+// diabaikan.
+func (r *trxRepositoryImpl) Settlement(tokoID uint, filter SubOrderFilter) (*models.SettlementReport, error) {
+	query := r.db.Model(&models.TrxToko{}).
+		Select("trxes.method_bayar, trx_tokos.cod_collected_at IS NOT NULL AS collected, COUNT(*) AS jumlah, COALESCE(SUM(trx_tokos.harga_total), 0) AS total").
+		Joins("JOIN trxes ON trxes.id = trx_tokos.id_trx").
+		Where("trx_tokos.id_toko = ? AND trx_tokos.status IN ?", tokoID, settlementStatuses)
+	if filter.From != nil {
+		query = query.Where("trx_tokos.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("trx_tokos.created_at < ?", *filter.To)
+	}
+
+	var rows []struct {
+		MethodBayar string
+		Collected   bool
+		Jumlah      int
+		Total       int
+	}
+	err := query.Group("trxes.method_bayar, trx_tokos.cod_collected_at IS NOT NULL").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SettlementReport{}
+	for _, row := range rows {
+		if row.MethodBayar != models.PaymentMethodCOD {
+			report.NonCOD.JumlahPesanan += row.Jumlah
+			report.NonCOD.Total += row.Total
+			continue
+		}
+		report.COD.JumlahPesanan += row.Jumlah
+		report.COD.Total += row.Total
+		if row.Collected {
+			report.COD.Diterima += row.Total
+		} else {
+			report.COD.BelumDiterima += row.Total
+		}
+	}
+	return report, nil
+}
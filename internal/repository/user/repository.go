@@ -12,6 +12,7 @@ type UserRepository interface {
 	FindByID(id uint) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
 	FindByNoTelp(noTelp string) (*models.User, error)
+	FindByResellerStatus(status string) ([]models.User, error)
 	Update(user *models.User) error
 	Delete(id uint) error
 }
@@ -61,6 +62,12 @@ func (r *userRepositoryImpl) FindByNoTelp(noTelp string) (*models.User, error) {
 	return &user, err
 }
 
+func (r *userRepositoryImpl) FindByResellerStatus(status string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("reseller_status = ?", status).Order("updated_at").Find(&users).Error
+	return users, err
+}
+
 func (r *userRepositoryImpl) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
@@ -0,0 +1,133 @@
+package voucher_repository
+
+import (
+	"errors"
+	"time"
+
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrVoucherUnavailable = errors.New("voucher is no longer available")
+
+type VoucherRepository interface {
+	Create(voucher *models.Voucher) error
+	FindByID(id uint) (*models.Voucher, error)
+	FindByKode(kode string, withDeleted bool) (*models.Voucher, error)
+	FindByUserID(userID uint) ([]models.Voucher, error)
+	CountRedeemed(voucherID, userID uint) (int64, error)
+	Delete(id uint) error
+}
+
+type voucherRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepositoryImpl{db: db}
+}
+
+func (r *voucherRepositoryImpl) Create(voucher *models.Voucher) error {
+	return r.db.Create(voucher).Error
+}
+
+func (r *voucherRepositoryImpl) FindByID(id uint) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := r.db.First(&voucher, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &voucher, err
+}
+
+// FindByKode mencari voucher berdasarkan kode. withDeleted ikut mencari
+// voucher yang sudah dihapus, dipakai untuk mengecek kode yang sudah
+// pernah terpakai karena kolom kode unik.
+func (r *voucherRepositoryImpl) FindByKode(kode string, withDeleted bool) (*models.Voucher, error) {
+	db := r.db
+	if withDeleted {
+		db = db.Unscoped()
+	}
+	var voucher models.Voucher
+	err := db.Where("kode = ?", kode).First(&voucher).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return &voucher, err
+}
+
+func (r *voucherRepositoryImpl) FindByUserID(userID uint) ([]models.Voucher, error) {
+	var vouchers []models.Voucher
+	err := r.db.Where("id_user = ?", userID).Order("mulai_at DESC").Find(&vouchers).Error
+	return vouchers, err
+}
+
+// CountRedeemed menghitung pemakaian voucher oleh userID yang masih
+// berlaku.
+func (r *voucherRepositoryImpl) CountRedeemed(voucherID, userID uint) (int64, error) {
+	return countRedeemed(r.db, voucherID, userID)
+}
+
+func countRedeemed(db *gorm.DB, voucherID, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.VoucherRedemption{}).
+		Where("id_voucher = ? AND id_user = ? AND status = ?", voucherID, userID, models.VoucherRedeemed).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *voucherRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&models.Voucher{}, id).Error
+}
+
+// ClaimTx memakai kuota voucher untuk redemption yang sudah disimpan di
+// dalam tx. Terpakai dinaikkan dengan update bersyarat yang sekaligus
+// mengunci baris voucher, sehingga hitungan pemakaian per user sesudahnya
+// tidak bisa didahului checkout lain dengan voucher yang sama.
+func ClaimTx(tx *gorm.DB, redemption *models.VoucherRedemption, now time.Time) error {
+	res := tx.Model(&models.Voucher{}).
+		Where("id = ? AND mulai_at <= ? AND selesai_at > ?", redemption.IDVoucher, now, now).
+		Where("kuota IS NULL OR terpakai < kuota").
+		Update("terpakai", gorm.Expr("terpakai + 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrVoucherUnavailable
+	}
+
+	var voucher models.Voucher
+	if err := tx.First(&voucher, redemption.IDVoucher).Error; err != nil {
+		return err
+	}
+	if voucher.KuotaPerUser != nil {
+		count, err := countRedeemed(tx, redemption.IDVoucher, redemption.IDUser)
+		if err != nil {
+			return err
+		}
+		if count > int64(*voucher.KuotaPerUser) {
+			return ErrVoucherUnavailable
+		}
+	}
+	return nil
+}
+
+// ReleaseTx melepas voucher yang dipakai transaksi trxID dan mengembalikan
+// kuotanya. Tidak melakukan apa-apa bila transaksi tidak memakai voucher.
+func ReleaseTx(tx *gorm.DB, trxID uint) error {
+	var redemption models.VoucherRedemption
+	err := tx.Where("id_trx = ? AND status = ?", trxID, models.VoucherRedeemed).First(&redemption).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&redemption).Update("status", models.VoucherReleased).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Model(&models.Voucher{}).
+		Where("id = ? AND terpakai > 0", redemption.IDVoucher).
+		Update("terpakai", gorm.Expr("terpakai - 1")).Error
+}
@@ -0,0 +1,47 @@
+package wishlist_repository
+
+import (
+	"test-rakamin/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository interface {
+	Add(userID, productID uint) error
+	Remove(userID, productID uint) (bool, error)
+	FindByUserID(userID uint) ([]models.Wishlist, error)
+	Exists(userID, productID uint) (bool, error)
+}
+
+type wishlistRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepositoryImpl{db: db}
+}
+
+// Add tidak mengembalikan error bila produk sudah ada di wishlist.
+func (r *wishlistRepositoryImpl) Add(userID, productID uint) error {
+	wishlist := models.Wishlist{IDUser: userID, ProductID: productID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&wishlist).Error
+}
+
+// Remove mengembalikan false bila produk tidak ada di wishlist user.
+func (r *wishlistRepositoryImpl) Remove(userID, productID uint) (bool, error) {
+	res := r.db.Unscoped().Where("id_user = ? AND product_id = ?", userID, productID).Delete(&models.Wishlist{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *wishlistRepositoryImpl) FindByUserID(userID uint) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+	err := r.db.Where("id_user = ?", userID).Order("id DESC").Find(&wishlists).Error
+	return wishlists, err
+}
+
+func (r *wishlistRepositoryImpl) Exists(userID, productID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Wishlist{}).Where("id_user = ? AND product_id = ?", userID, productID).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,221 @@
+package cart_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"test-rakamin/internal/models"
+	cart_repository "test-rakamin/internal/repository/cart"
+	product_repository "test-rakamin/internal/repository/product"
+	product_service "test-rakamin/internal/service/product"
+	trx_service "test-rakamin/internal/service/trx"
+)
+
+var (
+	ErrInvalidCartItem = errors.New("invalid cart item")
+	ErrItemNotFound    = errors.New("cart item not found")
+	ErrEmptyCart       = errors.New("cart is empty")
+)
+
+type CartService interface {
+	GetCart(userID uint) (*models.Cart, error)
+	AddItem(userID uint, payload *models.CartItemPayload) (*models.Cart, error)
+	UpdateItem(userID, id uint, kuantitas int) (*models.Cart, error)
+	RemoveItem(userID, id uint) error
+	ClearCart(userID uint) error
+	Checkout(userID uint, payload *models.CartCheckoutPayload) (*models.Trx, error)
+}
+
+type cartServiceImpl struct {
+	cartRepo       cart_repository.CartRepository
+	productRepo    product_repository.ProductRepository
+	productService product_service.ProductService
+	trxService     trx_service.TrxService
+}
+
+func NewCartService(repo cart_repository.CartRepository, productRepo product_repository.ProductRepository, productService product_service.ProductService, trxService trx_service.TrxService) CartService {
+	return &cartServiceImpl{cartRepo: repo, productRepo: productRepo, productService: productService, trxService: trxService}
+}
+
+// GetCart mengisi setiap item dengan harga efektif dan stok tersedia saat
+// ini. Item yang tidak bisa dibeli apa adanya diberi Peringatan dan tidak
+// ikut dihitung di Total.
+func (s *cartServiceImpl) GetCart(userID uint) (*models.Cart, error) {
+	items, err := s.cartRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.productService.GetProductsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	cart := &models.Cart{Items: items}
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		product, ok := byID[item.ProductID]
+		if !ok || product.Status != models.ProductPublished {
+			item.Peringatan = "product is no longer available"
+			continue
+		}
+		item.Product = product
+
+		harga, stok := product.HargaEfektif, product.StokTersedia
+		if item.VariantID != nil || len(product.ProductVariant) > 0 {
+			variant := findVariant(&item.Product, item.VariantID)
+			if variant == nil {
+				item.Peringatan = "please choose an available variant"
+				continue
+			}
+			item.Variant = variant
+			harga, stok = variant.HargaEfektif, variant.StokTersedia
+		}
+
+		item.HargaSatuan = harga
+		item.Subtotal = harga * item.Kuantitas
+		switch {
+		case stok == 0:
+			item.Peringatan = "out of stock"
+		case item.Kuantitas > stok:
+			item.Peringatan = fmt.Sprintf("only %d left in stock", stok)
+		default:
+			cart.Total += item.Subtotal
+		}
+	}
+	return cart, nil
+}
+
+// AddItem menambah kuantitas bila produk dan varian yang sama sudah ada di
+// keranjang.
+func (s *cartServiceImpl) AddItem(userID uint, payload *models.CartItemPayload) (*models.Cart, error) {
+	if payload.Kuantitas <= 0 {
+		return nil, fmt.Errorf("%w: kuantitas must be positive", ErrInvalidCartItem)
+	}
+	product, err := s.productRepo.FindByID(payload.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || product.Status != models.ProductPublished {
+		return nil, fmt.Errorf("%w: product with ID %d not found", ErrInvalidCartItem, payload.ProductID)
+	}
+	if len(product.ProductVariant) > 0 {
+		if findVariant(product, payload.VariantID) == nil {
+			return nil, fmt.Errorf("%w: a valid variant_id is required for product %s", ErrInvalidCartItem, product.NamaProduct)
+		}
+	} else if payload.VariantID != nil {
+		return nil, fmt.Errorf("%w: product %s has no variants", ErrInvalidCartItem, product.NamaProduct)
+	}
+
+	item, err := s.cartRepo.FindItem(userID, payload.ProductID, payload.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	if item != nil {
+		item.Kuantitas += payload.Kuantitas
+		err = s.cartRepo.Update(item)
+	} else {
+		err = s.cartRepo.Create(&models.CartItem{
+			IDUser:    userID,
+			ProductID: payload.ProductID,
+			VariantID: payload.VariantID,
+			Kuantitas: payload.Kuantitas,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCart(userID)
+}
+
+func (s *cartServiceImpl) UpdateItem(userID, id uint, kuantitas int) (*models.Cart, error) {
+	if kuantitas <= 0 {
+		return nil, fmt.Errorf("%w: kuantitas must be positive", ErrInvalidCartItem)
+	}
+	item, err := s.cartRepo.FindByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	item.Kuantitas = kuantitas
+	if err := s.cartRepo.Update(item); err != nil {
+		return nil, err
+	}
+	return s.GetCart(userID)
+}
+
+func (s *cartServiceImpl) RemoveItem(userID, id uint) error {
+	found, err := s.cartRepo.Delete(userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+func (s *cartServiceImpl) ClearCart(userID uint) error {
+	return s.cartRepo.Clear(userID)
+}
+
+// Checkout membuat transaksi dari seluruh isi keranjang lewat
+// TrxService.CreateTrx, lalu mengeluarkan item yang sudah di-checkout.
+// Item yang ditambahkan selama checkout berjalan tetap di keranjang.
+func (s *cartServiceImpl) Checkout(userID uint, payload *models.CartCheckoutPayload) (*models.Trx, error) {
+	items, err := s.cartRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	trxPayload := &models.TrxPayload{
+		MethodBayar: payload.MethodBayar,
+		AlamatKirim: payload.AlamatKirim,
+		VoucherCode: payload.VoucherCode,
+		Kurir:       payload.Kurir,
+		DetailTrx:   make([]models.DetailTrxPayload, 0, len(items)),
+	}
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		trxPayload.DetailTrx = append(trxPayload.DetailTrx, models.DetailTrxPayload{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Kuantitas: item.Kuantitas,
+		})
+		ids = append(ids, item.ID)
+	}
+
+	trx, err := s.trxService.CreateTrx(userID, trxPayload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cartRepo.DeleteByIDs(userID, ids); err != nil {
+		log.Printf("Gagal mengosongkan keranjang user %d setelah checkout %s: %v", userID, trx.KodeInvoice, err)
+	}
+	return trx, nil
+}
+
+func findVariant(product *models.Product, variantID *uint) *models.ProductVariant {
+	if variantID == nil {
+		return nil
+	}
+	for i := range product.ProductVariant {
+		if product.ProductVariant[i].ID == *variantID {
+			return &product.ProductVariant[i]
+		}
+	}
+	return nil
+}
@@ -2,30 +2,90 @@ package category_service
 
 import (
 	"errors"
+	"fmt"
 	"test-rakamin/internal/models"
 	category_repository "test-rakamin/internal/repository/category"
+	user_repository "test-rakamin/internal/repository/user"
+)
+
+var (
+	ErrForbidden     = errors.New("admin access required")
+	ErrInvalidParent = errors.New("invalid parent category")
+	ErrInvalidTarget = errors.New("invalid target category")
+	// ErrCategoryInUse dikembalikan saat kategori yang masih dipakai dihapus
+	// tanpa kategori tujuan.
+	ErrCategoryInUse = category_repository.ErrCategoryInUse
 )
 
 type CategoryService interface {
 	GetAllCategories() ([]models.Category, error)
+	GetCategoryTree() ([]models.Category, error)
 	GetCategoryByID(id uint) (*models.Category, error)
-	CreateCategory(category *models.Category) (*models.Category, error)
-	UpdateCategory(id uint, updatedCategory *models.Category) (*models.Category, error)
-	DeleteCategory(id uint) error
+	GetBreadcrumbs(id uint) ([]models.Category, error)
+	GetDescendantIDs(id uint) ([]uint, error)
+	CreateCategory(userID uint, category *models.Category) (*models.Category, error)
+	UpdateCategory(userID, id uint, updatedCategory *models.Category) (*models.Category, error)
+	DeleteCategory(userID, id uint, moveTo *uint) error
 }
 
 type categoryServiceImpl struct {
 	categoryRepo category_repository.CategoryRepository
+	userRepo     user_repository.UserRepository
 }
 
-func NewCategoryService(repo category_repository.CategoryRepository) CategoryService {
-	return &categoryServiceImpl{categoryRepo: repo}
+func NewCategoryService(repo category_repository.CategoryRepository, userRepo user_repository.UserRepository) CategoryService {
+	return &categoryServiceImpl{categoryRepo: repo, userRepo: userRepo}
 }
 
 func (s *categoryServiceImpl) GetAllCategories() ([]models.Category, error) {
 	return s.categoryRepo.FindAll()
 }
 
+// GetCategoryTree mengembalikan kategori paling atas beserta seluruh
+// turunannya di field Children. Kategori yang induknya sudah dihapus
+// ditampilkan sebagai kategori paling atas.
+func (s *categoryServiceImpl) GetCategoryTree() ([]models.Category, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Category, len(categories))
+	children := map[uint][]uint{}
+	var roots []uint
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			if _, ok := byID[*category.ParentID]; ok {
+				children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+				continue
+			}
+		}
+		roots = append(roots, category.ID)
+	}
+
+	var build func(id uint, visited map[uint]bool) models.Category
+	build = func(id uint, visited map[uint]bool) models.Category {
+		category := byID[id]
+		visited[id] = true
+		category.Children = []models.Category{}
+		for _, childID := range children[id] {
+			if !visited[childID] {
+				category.Children = append(category.Children, build(childID, visited))
+			}
+		}
+		return category
+	}
+
+	tree := make([]models.Category, 0, len(roots))
+	visited := map[uint]bool{}
+	for _, id := range roots {
+		tree = append(tree, build(id, visited))
+	}
+	return tree, nil
+}
+
 func (s *categoryServiceImpl) GetCategoryByID(id uint) (*models.Category, error) {
 	category, err := s.categoryRepo.FindByID(id)
 	if err != nil {
@@ -37,7 +97,63 @@ func (s *categoryServiceImpl) GetCategoryByID(id uint) (*models.Category, error)
 	return category, nil
 }
 
-func (s *categoryServiceImpl) CreateCategory(category *models.Category) (*models.Category, error) {
+// GetBreadcrumbs mengembalikan urutan kategori dari yang paling atas sampai
+// kategori id.
+func (s *categoryServiceImpl) GetBreadcrumbs(id uint) ([]models.Category, error) {
+	byID, err := s.categoryMap()
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []models.Category
+	visited := map[uint]bool{}
+	for current, ok := byID[id]; ok && !visited[current.ID]; current, ok = parentOf(byID, current) {
+		visited[current.ID] = true
+		chain = append(chain, current)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// GetDescendantIDs mengembalikan id beserta id seluruh kategori turunannya.
+func (s *categoryServiceImpl) GetDescendantIDs(id uint) ([]uint, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	children := map[uint][]uint{}
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{id}
+	visited := map[uint]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range children[ids[i]] {
+			if !visited[childID] {
+				visited[childID] = true
+				ids = append(ids, childID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (s *categoryServiceImpl) CreateCategory(userID uint, category *models.Category) (*models.Category, error) {
+	if err := s.ensureAdmin(userID); err != nil {
+		return nil, err
+	}
+	// Turunan dibuat lewat endpoint masing-masing, bukan dari body induknya.
+	category.Children = nil
+	if category.ParentID != nil {
+		if err := s.validateParent(0, *category.ParentID); err != nil {
+			return nil, err
+		}
+	}
 	err := s.categoryRepo.Create(category)
 	if err != nil {
 		return nil, err
@@ -45,7 +161,10 @@ func (s *categoryServiceImpl) CreateCategory(category *models.Category) (*models
 	return category, nil
 }
 
-func (s *categoryServiceImpl) UpdateCategory(id uint, updatedCategory *models.Category) (*models.Category, error) {
+func (s *categoryServiceImpl) UpdateCategory(userID, id uint, updatedCategory *models.Category) (*models.Category, error) {
+	if err := s.ensureAdmin(userID); err != nil {
+		return nil, err
+	}
 	existingCategory, err := s.categoryRepo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -53,7 +172,13 @@ func (s *categoryServiceImpl) UpdateCategory(id uint, updatedCategory *models.Ca
 	if existingCategory == nil {
 		return nil, errors.New("category not found")
 	}
+	if updatedCategory.ParentID != nil {
+		if err := s.validateParent(id, *updatedCategory.ParentID); err != nil {
+			return nil, err
+		}
+	}
 	existingCategory.NamaCategory = updatedCategory.NamaCategory
+	existingCategory.ParentID = updatedCategory.ParentID
 	err = s.categoryRepo.Update(existingCategory)
 	if err != nil {
 		return nil, err
@@ -61,7 +186,56 @@ func (s *categoryServiceImpl) UpdateCategory(id uint, updatedCategory *models.Ca
 	return existingCategory, nil
 }
 
-func (s *categoryServiceImpl) DeleteCategory(id uint) error {
+// validateParent memastikan parentID ada dan bukan kategori id itu sendiri
+// atau turunannya, supaya hierarki tidak membentuk siklus. id 0 dipakai
+// untuk kategori yang baru dibuat.
+func (s *categoryServiceImpl) validateParent(id, parentID uint) error {
+	byID, err := s.categoryMap()
+	if err != nil {
+		return err
+	}
+	parent, ok := byID[parentID]
+	if !ok {
+		return fmt.Errorf("%w: category %d not found", ErrInvalidParent, parentID)
+	}
+
+	visited := map[uint]bool{}
+	for current, ok := parent, true; ok && !visited[current.ID]; current, ok = parentOf(byID, current) {
+		if current.ID == id {
+			return fmt.Errorf("%w: a category cannot be placed under itself or its descendants", ErrInvalidParent)
+		}
+		visited[current.ID] = true
+	}
+	return nil
+}
+
+func (s *categoryServiceImpl) categoryMap() (map[uint]models.Category, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	return byID, nil
+}
+
+func parentOf(byID map[uint]models.Category, category models.Category) (models.Category, bool) {
+	if category.ParentID == nil {
+		return models.Category{}, false
+	}
+	parent, ok := byID[*category.ParentID]
+	return parent, ok
+}
+
+// DeleteCategory menolak menghapus kategori yang masih dipakai produk atau
+// subkategori, kecuali moveTo diisi. Produk dan subkategori kemudian
+// dipindahkan ke moveTo dalam transaksi yang sama dengan penghapusannya.
+func (s *categoryServiceImpl) DeleteCategory(userID, id uint, moveTo *uint) error {
+	if err := s.ensureAdmin(userID); err != nil {
+		return err
+	}
 	category, err := s.categoryRepo.FindByID(id)
 	if err != nil {
 		return err
@@ -69,5 +243,35 @@ func (s *categoryServiceImpl) DeleteCategory(id uint) error {
 	if category == nil {
 		return errors.New("category not found")
 	}
-	return s.categoryRepo.Delete(id)
+
+	if moveTo != nil {
+		target, err := s.categoryRepo.FindByID(*moveTo)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return fmt.Errorf("%w: category %d not found", ErrInvalidTarget, *moveTo)
+		}
+		descendants, err := s.GetDescendantIDs(id)
+		if err != nil {
+			return err
+		}
+		for _, descendantID := range descendants {
+			if descendantID == *moveTo {
+				return fmt.Errorf("%w: target cannot be the deleted category or one of its descendants", ErrInvalidTarget)
+			}
+		}
+	}
+	return s.categoryRepo.Delete(id, userID, moveTo)
+}
+
+func (s *categoryServiceImpl) ensureAdmin(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsAdmin {
+		return ErrForbidden
+	}
+	return nil
 }
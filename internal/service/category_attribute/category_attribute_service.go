@@ -0,0 +1,296 @@
+package category_attribute_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"test-rakamin/internal/models"
+	category_repository "test-rakamin/internal/repository/category"
+	category_attribute_repository "test-rakamin/internal/repository/category_attribute"
+	user_repository "test-rakamin/internal/repository/user"
+	category_service "test-rakamin/internal/service/category"
+)
+
+var (
+	ErrForbidden         = errors.New("admin access required")
+	ErrInvalidAttribute  = errors.New("invalid attribute definition")
+	ErrInvalidAttributes = errors.New("invalid product attributes")
+)
+
+var kodePattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+// maxNilaiLength sama dengan panjang kolom ProductAttribute.Nilai.
+const maxNilaiLength = 255
+
+type CategoryAttributeService interface {
+	GetSchema(categoryID uint) ([]models.CategoryAttribute, error)
+	CreateAttribute(userID, categoryID uint, payload *models.CategoryAttributePayload) (*models.CategoryAttribute, error)
+	UpdateAttribute(userID, categoryID, id uint, payload *models.CategoryAttributePayload) (*models.CategoryAttribute, error)
+	DeleteAttribute(userID, categoryID, id uint) error
+	ValidateValues(categoryID uint, raw string) ([]models.ProductAttribute, error)
+}
+
+type categoryAttributeServiceImpl struct {
+	attributeRepo   category_attribute_repository.CategoryAttributeRepository
+	categoryRepo    category_repository.CategoryRepository
+	userRepo        user_repository.UserRepository
+	categoryService category_service.CategoryService
+}
+
+func NewCategoryAttributeService(repo category_attribute_repository.CategoryAttributeRepository, categoryRepo category_repository.CategoryRepository, userRepo user_repository.UserRepository, categoryService category_service.CategoryService) CategoryAttributeService {
+	return &categoryAttributeServiceImpl{attributeRepo: repo, categoryRepo: categoryRepo, userRepo: userRepo, categoryService: categoryService}
+}
+
+// GetSchema mengembalikan atribut yang berlaku untuk categoryID, termasuk
+// atribut dari kategori induknya. Bila kode yang sama didefinisikan lagi di
+// subkategori, definisi subkategori yang dipakai.
+func (s *categoryAttributeServiceImpl) GetSchema(categoryID uint) ([]models.CategoryAttribute, error) {
+	breadcrumbs, err := s.categoryService.GetBreadcrumbs(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(breadcrumbs) == 0 {
+		return nil, errors.New("category not found")
+	}
+	ids := make([]uint, 0, len(breadcrumbs))
+	for _, category := range breadcrumbs {
+		ids = append(ids, category.ID)
+	}
+	attributes, err := s.attributeRepo.FindByCategoryIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := []models.CategoryAttribute{}
+	position := map[string]int{}
+	for _, id := range ids {
+		for _, attribute := range attributes {
+			if attribute.CategoryID != id {
+				continue
+			}
+			if i, ok := position[attribute.Kode]; ok {
+				schema[i] = attribute
+				continue
+			}
+			position[attribute.Kode] = len(schema)
+			schema = append(schema, attribute)
+		}
+	}
+	return schema, nil
+}
+
+func (s *categoryAttributeServiceImpl) CreateAttribute(userID, categoryID uint, payload *models.CategoryAttributePayload) (*models.CategoryAttribute, error) {
+	if err := s.ensureAdmin(userID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCategory(categoryID); err != nil {
+		return nil, err
+	}
+
+	attribute := &models.CategoryAttribute{CategoryID: categoryID}
+	if err := s.applyPayload(attribute, payload); err != nil {
+		return nil, err
+	}
+	if err := s.attributeRepo.Create(attribute); err != nil {
+		return nil, err
+	}
+	return attribute, nil
+}
+
+func (s *categoryAttributeServiceImpl) UpdateAttribute(userID, categoryID, id uint, payload *models.CategoryAttributePayload) (*models.CategoryAttribute, error) {
+	if err := s.ensureAdmin(userID); err != nil {
+		return nil, err
+	}
+	attribute, err := s.findAttribute(categoryID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyPayload(attribute, payload); err != nil {
+		return nil, err
+	}
+	if err := s.attributeRepo.Update(attribute); err != nil {
+		return nil, err
+	}
+	return attribute, nil
+}
+
+// DeleteAttribute tidak menghapus nilai atribut yang sudah tersimpan di
+// produk; nilai tersebut hanya tidak divalidasi lagi.
+func (s *categoryAttributeServiceImpl) DeleteAttribute(userID, categoryID, id uint) error {
+	if err := s.ensureAdmin(userID); err != nil {
+		return err
+	}
+	if _, err := s.findAttribute(categoryID, id); err != nil {
+		return err
+	}
+	return s.attributeRepo.Delete(id)
+}
+
+func (s *categoryAttributeServiceImpl) applyPayload(attribute *models.CategoryAttribute, payload *models.CategoryAttributePayload) error {
+	kode := strings.ToLower(strings.TrimSpace(payload.Kode))
+	if !kodePattern.MatchString(kode) {
+		return fmt.Errorf("%w: kode must be 1-50 lowercase letters, digits or underscores", ErrInvalidAttribute)
+	}
+	existing, err := s.attributeRepo.FindByKode(attribute.CategoryID, kode)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != attribute.ID {
+		return fmt.Errorf("%w: kode %s already exists in this category", ErrInvalidAttribute, kode)
+	}
+
+	nama := strings.TrimSpace(payload.Nama)
+	if nama == "" {
+		nama = kode
+	}
+
+	var pilihan []string
+	switch payload.Tipe {
+	case models.AttributeString, models.AttributeNumber, models.AttributeBoolean:
+	case models.AttributeEnum:
+		for _, option := range payload.Pilihan {
+			option = strings.TrimSpace(option)
+			if option != "" && len(option) <= maxNilaiLength && !slices.Contains(pilihan, option) {
+				pilihan = append(pilihan, option)
+			}
+		}
+		if len(pilihan) == 0 {
+			return fmt.Errorf("%w: enum attributes need at least one option", ErrInvalidAttribute)
+		}
+	default:
+		return fmt.Errorf("%w: tipe must be string, number, enum or boolean", ErrInvalidAttribute)
+	}
+
+	attribute.Kode = kode
+	attribute.Nama = nama
+	attribute.Tipe = payload.Tipe
+	attribute.Wajib = payload.Wajib
+	attribute.Pilihan = pilihan
+	return nil
+}
+
+// ValidateValues memeriksa raw, yaitu objek JSON kode atribut ke nilai,
+// terhadap skema kategori dan mengembalikan nilai yang sudah
+// dinormalisasi. raw kosong diperlakukan sebagai objek kosong.
+func (s *categoryAttributeServiceImpl) ValidateValues(categoryID uint, raw string) ([]models.ProductAttribute, error) {
+	values := map[string]interface{}{}
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &values); err != nil {
+			return nil, fmt.Errorf("%w: atribut must be a JSON object of attribute codes to values", ErrInvalidAttributes)
+		}
+	}
+
+	schema, err := s.GetSchema(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	byKode := make(map[string]models.CategoryAttribute, len(schema))
+	for _, attribute := range schema {
+		byKode[attribute.Kode] = attribute
+	}
+	for kode := range values {
+		if _, ok := byKode[kode]; !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %s", ErrInvalidAttributes, kode)
+		}
+	}
+
+	result := []models.ProductAttribute{}
+	for _, attribute := range schema {
+		value, ok := values[attribute.Kode]
+		if text, isText := value.(string); isText && strings.TrimSpace(text) == "" {
+			ok = false
+		}
+		if !ok || value == nil {
+			if attribute.Wajib {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidAttributes, attribute.Kode)
+			}
+			continue
+		}
+		nilai, err := normalize(attribute, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidAttributes, attribute.Kode, err)
+		}
+		result = append(result, models.ProductAttribute{Kode: attribute.Kode, Nilai: nilai})
+	}
+	return result, nil
+}
+
+// normalize mengubah nilai JSON menjadi teks yang disimpan. Angka dan
+// boolean juga diterima dalam bentuk string, misalnya dari form.
+func normalize(attribute models.CategoryAttribute, value interface{}) (string, error) {
+	switch attribute.Tipe {
+	case models.AttributeNumber:
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case string:
+			if number, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return strconv.FormatFloat(number, 'f', -1, 64), nil
+			}
+		}
+		return "", errors.New("must be a number")
+	case models.AttributeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return strconv.FormatBool(b), nil
+			}
+		}
+		return "", errors.New("must be true or false")
+	case models.AttributeEnum:
+		if v, ok := value.(string); ok && slices.Contains(attribute.Pilihan, strings.TrimSpace(v)) {
+			return strings.TrimSpace(v), nil
+		}
+		return "", fmt.Errorf("must be one of %s", strings.Join(attribute.Pilihan, ", "))
+	default:
+		v, ok := value.(string)
+		if !ok {
+			return "", errors.New("must be a string")
+		}
+		v = strings.TrimSpace(v)
+		if len(v) > maxNilaiLength {
+			return "", fmt.Errorf("must be at most %d characters", maxNilaiLength)
+		}
+		return v, nil
+	}
+}
+
+func (s *categoryAttributeServiceImpl) findAttribute(categoryID, id uint) (*models.CategoryAttribute, error) {
+	attribute, err := s.attributeRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if attribute == nil || attribute.CategoryID != categoryID {
+		return nil, errors.New("attribute not found")
+	}
+	return attribute, nil
+}
+
+func (s *categoryAttributeServiceImpl) ensureCategory(categoryID uint) error {
+	category, err := s.categoryRepo.FindByID(categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return errors.New("category not found")
+	}
+	return nil
+}
+
+func (s *categoryAttributeServiceImpl) ensureAdmin(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
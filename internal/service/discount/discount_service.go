@@ -0,0 +1,281 @@
+package discount_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"test-rakamin/internal/models"
+	discount_repository "test-rakamin/internal/repository/discount"
+	product_repository "test-rakamin/internal/repository/product"
+	toko_repository "test-rakamin/internal/repository/toko"
+	user_repository "test-rakamin/internal/repository/user"
+	category_service "test-rakamin/internal/service/category"
+)
+
+var (
+	ErrForbidden       = errors.New("you are not allowed to manage this discount")
+	ErrInvalidDiscount = errors.New("invalid discount")
+	ErrNotFound        = errors.New("discount not found")
+)
+
+type DiscountService interface {
+	GetActiveDiscounts() ([]models.Discount, error)
+	GetMyDiscounts(userID uint) ([]models.Discount, error)
+	CreateDiscount(userID uint, payload *models.DiscountPayload) (*models.Discount, error)
+	DeleteDiscount(userID, id uint) error
+	ActiveFor(products ...*models.Product) (map[uint][]models.Discount, error)
+	FillEffectivePrice(products ...*models.Product) error
+}
+
+type discountServiceImpl struct {
+	discountRepo    discount_repository.DiscountRepository
+	productRepo     product_repository.ProductRepository
+	tokoRepo        toko_repository.TokoRepository
+	userRepo        user_repository.UserRepository
+	categoryService category_service.CategoryService
+}
+
+func NewDiscountService(repo discount_repository.DiscountRepository, productRepo product_repository.ProductRepository, tokoRepo toko_repository.TokoRepository, userRepo user_repository.UserRepository, categoryService category_service.CategoryService) DiscountService {
+	return &discountServiceImpl{discountRepo: repo, productRepo: productRepo, tokoRepo: tokoRepo, userRepo: userRepo, categoryService: categoryService}
+}
+
+func (s *discountServiceImpl) GetActiveDiscounts() ([]models.Discount, error) {
+	return s.discountRepo.FindActive(time.Now())
+}
+
+func (s *discountServiceImpl) GetMyDiscounts(userID uint) ([]models.Discount, error) {
+	return s.discountRepo.FindByUserID(userID)
+}
+
+// CreateDiscount hanya bisa dilakukan admin atau pemilik toko untuk produk
+// dan tokonya sendiri. Diskon kategori hanya bisa dibuat admin.
+func (s *discountServiceImpl) CreateDiscount(userID uint, payload *models.DiscountPayload) (*models.Discount, error) {
+	discount := &models.Discount{
+		IDUser:    userID,
+		Nama:      strings.TrimSpace(payload.Nama),
+		Tipe:      strings.ToLower(payload.Tipe),
+		Nilai:     payload.Nilai,
+		Cakupan:   strings.ToLower(payload.Cakupan),
+		TargetID:  payload.TargetID,
+		MulaiAt:   payload.MulaiAt,
+		SelesaiAt: payload.SelesaiAt,
+		Kuota:     payload.Kuota,
+	}
+	if err := validateDiscount(discount); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCanManage(userID, discount); err != nil {
+		return nil, err
+	}
+	if err := s.discountRepo.Create(discount); err != nil {
+		return nil, err
+	}
+	return discount, nil
+}
+
+func validateDiscount(discount *models.Discount) error {
+	if discount.Nama == "" {
+		return fmt.Errorf("%w: nama is required", ErrInvalidDiscount)
+	}
+	switch discount.Tipe {
+	case models.DiscountPercent:
+		if discount.Nilai < 1 || discount.Nilai > 100 {
+			return fmt.Errorf("%w: nilai for percent discount must be between 1 and 100", ErrInvalidDiscount)
+		}
+	case models.DiscountFixed:
+		if discount.Nilai <= 0 {
+			return fmt.Errorf("%w: nilai must be positive", ErrInvalidDiscount)
+		}
+	default:
+		return fmt.Errorf("%w: tipe must be %s or %s", ErrInvalidDiscount, models.DiscountPercent, models.DiscountFixed)
+	}
+	switch discount.Cakupan {
+	case models.DiscountScopeProduct, models.DiscountScopeCategory, models.DiscountScopeToko:
+	default:
+		return fmt.Errorf("%w: cakupan must be %s, %s or %s", ErrInvalidDiscount, models.DiscountScopeProduct, models.DiscountScopeCategory, models.DiscountScopeToko)
+	}
+	if discount.TargetID == 0 {
+		return fmt.Errorf("%w: target_id is required", ErrInvalidDiscount)
+	}
+
+	// mulai_at kosong berarti diskon langsung berlaku.
+	if discount.MulaiAt.IsZero() {
+		discount.MulaiAt = time.Now()
+	}
+	if !discount.SelesaiAt.After(discount.MulaiAt) {
+		return fmt.Errorf("%w: selesai_at must be after mulai_at", ErrInvalidDiscount)
+	}
+	if !discount.SelesaiAt.After(time.Now()) {
+		return fmt.Errorf("%w: selesai_at must be in the future", ErrInvalidDiscount)
+	}
+	if discount.Kuota != nil && *discount.Kuota <= 0 {
+		return fmt.Errorf("%w: kuota must be positive", ErrInvalidDiscount)
+	}
+	return nil
+}
+
+// ensureCanManage memastikan target diskon ada dan userID boleh mengaturnya.
+func (s *discountServiceImpl) ensureCanManage(userID uint, discount *models.Discount) error {
+	isAdmin, err := s.isAdmin(userID)
+	if err != nil {
+		return err
+	}
+
+	var ownerID uint
+	switch discount.Cakupan {
+	case models.DiscountScopeProduct:
+		product, err := s.productRepo.FindByID(discount.TargetID)
+		if err != nil {
+			return err
+		}
+		if product == nil {
+			return fmt.Errorf("%w: product %d not found", ErrInvalidDiscount, discount.TargetID)
+		}
+		ownerID = product.Toko.IDUser
+	case models.DiscountScopeToko:
+		toko, err := s.tokoRepo.FindByID(discount.TargetID)
+		if err != nil {
+			return err
+		}
+		if toko == nil {
+			return fmt.Errorf("%w: toko %d not found", ErrInvalidDiscount, discount.TargetID)
+		}
+		ownerID = toko.IDUser
+	case models.DiscountScopeCategory:
+		if _, err := s.categoryService.GetCategoryByID(discount.TargetID); err != nil {
+			return fmt.Errorf("%w: category %d not found", ErrInvalidDiscount, discount.TargetID)
+		}
+	}
+
+	if !isAdmin && (ownerID == 0 || ownerID != userID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *discountServiceImpl) isAdmin(userID uint) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsAdmin, nil
+}
+
+// DeleteDiscount menghentikan diskon dan hanya bisa dilakukan pembuatnya
+// atau admin. Transaksi yang sudah memakai diskon tetap memakai harga yang
+// tercatat di DetailTrx.
+func (s *discountServiceImpl) DeleteDiscount(userID, id uint) error {
+	discount, err := s.discountRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if discount == nil {
+		return ErrNotFound
+	}
+	if discount.IDUser != userID {
+		isAdmin, err := s.isAdmin(userID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return ErrForbidden
+		}
+	}
+	return s.discountRepo.Delete(id)
+}
+
+// ActiveFor mengembalikan diskon yang sedang berlaku untuk setiap produk,
+// dikelompokkan berdasarkan ID produk.
+func (s *discountServiceImpl) ActiveFor(products ...*models.Product) (map[uint][]models.Discount, error) {
+	result := map[uint][]models.Discount{}
+	if len(products) == 0 {
+		return result, nil
+	}
+	discounts, err := s.discountRepo.FindActive(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Diskon kategori ikut berlaku untuk semua subkategorinya.
+	categoryIDs := map[uint]map[uint]bool{}
+	for _, discount := range discounts {
+		if discount.Cakupan != models.DiscountScopeCategory || categoryIDs[discount.TargetID] != nil {
+			continue
+		}
+		ids, err := s.categoryService.GetDescendantIDs(discount.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		categoryIDs[discount.TargetID] = map[uint]bool{}
+		for _, id := range ids {
+			categoryIDs[discount.TargetID][id] = true
+		}
+	}
+
+	for _, product := range products {
+		for _, discount := range discounts {
+			var applies bool
+			switch discount.Cakupan {
+			case models.DiscountScopeProduct:
+				applies = discount.TargetID == product.ID
+			case models.DiscountScopeToko:
+				applies = discount.TargetID == product.IDToko
+			case models.DiscountScopeCategory:
+				applies = categoryIDs[discount.TargetID][product.IDCategory]
+			}
+			if applies {
+				result[product.ID] = append(result[product.ID], discount)
+			}
+		}
+	}
+	return result, nil
+}
+
+// FillEffectivePrice mengisi HargaEfektif dan Diskon produk beserta
+// HargaEfektif variannya.
+func (s *discountServiceImpl) FillEffectivePrice(products ...*models.Product) error {
+	active, err := s.ActiveFor(products...)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		discounts := active[product.ID]
+		product.Diskon, product.HargaEfektif = Best(discounts, product.HargaKonsumen)
+		for i := range product.ProductVariant {
+			variant := &product.ProductVariant[i]
+			harga := product.HargaKonsumen
+			if variant.HargaKonsumen != nil {
+				harga = *variant.HargaKonsumen
+			}
+			_, variant.HargaEfektif = Best(discounts, harga)
+		}
+	}
+	return nil
+}
+
+// Apply mengembalikan harga setelah dipotong discount, tidak pernah
+// kurang dari 0.
+func Apply(discount models.Discount, harga int) int {
+	switch discount.Tipe {
+	case models.DiscountPercent:
+		harga -= harga * discount.Nilai / 100
+	case models.DiscountFixed:
+		harga -= discount.Nilai
+	}
+	return max(harga, 0)
+}
+
+// Best memilih diskon yang menghasilkan harga paling rendah. Bila tidak
+// ada diskon yang menurunkan harga, hasilnya nil dan harga semula.
+func Best(discounts []models.Discount, harga int) (*models.Discount, int) {
+	var best *models.Discount
+	lowest := harga
+	for i := range discounts {
+		if discounted := Apply(discounts[i], harga); discounted < lowest {
+			best, lowest = &discounts[i], discounted
+		}
+	}
+	return best, lowest
+}
@@ -0,0 +1,46 @@
+package notification_service
+
+import (
+	"errors"
+
+	"test-rakamin/internal/models"
+	notification_repository "test-rakamin/internal/repository/notification"
+)
+
+type NotificationService interface {
+	Notify(notification *models.Notification) error
+	GetNotifications(userID uint, unreadOnly bool) ([]models.Notification, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) error
+}
+
+type notificationServiceImpl struct {
+	notificationRepo notification_repository.NotificationRepository
+}
+
+func NewNotificationService(repo notification_repository.NotificationRepository) NotificationService {
+	return &notificationServiceImpl{notificationRepo: repo}
+}
+
+func (s *notificationServiceImpl) Notify(notification *models.Notification) error {
+	return s.notificationRepo.Create(notification)
+}
+
+func (s *notificationServiceImpl) GetNotifications(userID uint, unreadOnly bool) ([]models.Notification, error) {
+	return s.notificationRepo.FindByUserID(userID, unreadOnly)
+}
+
+func (s *notificationServiceImpl) MarkRead(userID, id uint) error {
+	found, err := s.notificationRepo.MarkRead(id, userID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("notification not found")
+	}
+	return nil
+}
+
+func (s *notificationServiceImpl) MarkAllRead(userID uint) error {
+	return s.notificationRepo.MarkAllRead(userID)
+}
@@ -0,0 +1,34 @@
+package payment_service
+
+import (
+	"errors"
+	"testing"
+
+	"test-rakamin/pkg/payment"
+)
+
+func TestValidateCallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		callback payment.Callback
+		wantErr  bool
+	}{
+		{name: "paid", callback: payment.Callback{EventID: "e1", Reference: "INV-1", Status: payment.StatusPaid, Jumlah: 10000}},
+		{name: "failed tanpa jumlah", callback: payment.Callback{EventID: "e1", Reference: "INV-1", Status: payment.StatusFailed}},
+		{name: "event_id kosong", callback: payment.Callback{EventID: " ", Reference: "INV-1", Status: payment.StatusPaid}, wantErr: true},
+		{name: "reference kosong", callback: payment.Callback{EventID: "e1", Status: payment.StatusPaid}, wantErr: true},
+		{name: "status tidak dikenal", callback: payment.Callback{EventID: "e1", Reference: "INV-1", Status: "refunded"}, wantErr: true},
+		{name: "jumlah negatif", callback: payment.Callback{EventID: "e1", Reference: "INV-1", Status: payment.StatusPaid, Jumlah: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCallback(&tt.callback)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidCallback) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidCallback)
+			}
+		})
+	}
+}
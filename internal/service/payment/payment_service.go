@@ -0,0 +1,254 @@
+package payment_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"test-rakamin/internal/models"
+	payment_repository "test-rakamin/internal/repository/payment"
+	toko_repository "test-rakamin/internal/repository/toko"
+	notification_service "test-rakamin/internal/service/notification"
+	"test-rakamin/pkg/payment"
+)
+
+var (
+	ErrInvalidPayment   = errors.New("invalid payment method")
+	ErrProviderNotFound = errors.New("payment provider not found")
+	ErrInvalidSignature = errors.New("invalid payment signature")
+	ErrInvalidCallback  = errors.New("invalid payment callback")
+	ErrPaymentNotFound  = errors.New("payment not found")
+)
+
+type PaymentService interface {
+	Methods() []string
+	CheckMethod(trx *models.Trx) error
+	CreateCharge(trx *models.Trx) error
+	HandleWebhook(provider string, body []byte, signature string) error
+	SettleRefund(trxID uint) error
+	RetryRefunds() error
+}
+
+type paymentServiceImpl struct {
+	paymentRepo         payment_repository.PaymentRepository
+	providers           []payment.Provider
+	tokoRepo            toko_repository.TokoRepository
+	notificationService notification_service.NotificationService
+}
+
+func NewPaymentService(repo payment_repository.PaymentRepository, providers []payment.Provider, tokoRepo toko_repository.TokoRepository, notificationService notification_service.NotificationService) PaymentService {
+	return &paymentServiceImpl{paymentRepo: repo, providers: providers, tokoRepo: tokoRepo, notificationService: notificationService}
+}
+
+// Methods mengembalikan kode provider dan cod, yaitu nilai yang bisa
+// dipakai sebagai method_bayar.
+func (s *paymentServiceImpl) Methods() []string {
+	codes := make([]string, 0, len(s.providers)+1)
+	for _, provider := range s.providers {
+		codes = append(codes, provider.Code())
+	}
+	return append(codes, models.PaymentMethodCOD)
+}
+
+func (s *paymentServiceImpl) provider(code string) payment.Provider {
+	for _, provider := range s.providers {
+		if provider.Code() == strings.ToLower(strings.TrimSpace(code)) {
+			return provider
+		}
+	}
+	return nil
+}
+
+// CheckMethod memastikan method_bayar trx adalah provider yang aktif atau
+// cod lalu menormalkannya. Untuk COD juga diperiksa apakah setiap toko
+// menerima COD untuk sub-order tersebut. Dipanggil sebelum trx disimpan.
+func (s *paymentServiceImpl) CheckMethod(trx *models.Trx) error {
+	if strings.EqualFold(strings.TrimSpace(trx.MethodBayar), models.PaymentMethodCOD) {
+		trx.MethodBayar = models.PaymentMethodCOD
+		return s.checkCOD(trx)
+	}
+	provider := s.provider(trx.MethodBayar)
+	if provider == nil {
+		return fmt.Errorf("%w: method_bayar must be one of %s", ErrInvalidPayment, strings.Join(s.Methods(), ", "))
+	}
+	trx.MethodBayar = provider.Code()
+	return nil
+}
+
+// CreateCharge membuat tagihan di provider method_bayar trx, menyimpannya
+// sebagai Payment dan mengisi trx.Payment. Dipanggil setelah trx tersimpan
+// supaya tidak ada tagihan tanpa transaksi. Transaksi COD tidak ditagih.
+func (s *paymentServiceImpl) CreateCharge(trx *models.Trx) error {
+	if trx.MethodBayar == models.PaymentMethodCOD {
+		return nil
+	}
+	provider := s.provider(trx.MethodBayar)
+	if provider == nil {
+		return fmt.Errorf("%w: method_bayar must be one of %s", ErrInvalidPayment, strings.Join(s.Methods(), ", "))
+	}
+	charge, err := provider.CreateCharge(payment.ChargeRequest{
+		KodeInvoice: trx.KodeInvoice,
+		Jumlah:      trx.HargaTotal,
+		ExpiresAt:   *trx.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	record := &models.Payment{
+		IDTrx:     trx.ID,
+		Provider:  provider.Code(),
+		Reference: charge.Reference,
+		Jumlah:    trx.HargaTotal,
+		Status:    models.PaymentPending,
+		Instruksi: charge.Instruksi,
+	}
+	if err := s.paymentRepo.Create(record); err != nil {
+		return err
+	}
+	trx.Payment = record
+	return nil
+}
+
+// checkCOD memastikan pesanan dikirim kurir dan setiap toko menerima COD
+// untuk total sub-order-nya.
+func (s *paymentServiceImpl) checkCOD(trx *models.Trx) error {
+	if trx.Kurir == "" {
+		return fmt.Errorf("%w: cod requires a kurir", ErrInvalidPayment)
+	}
+	for _, sub := range trx.TrxToko {
+		toko, err := s.tokoRepo.FindByID(sub.IDToko)
+		if err != nil {
+			return err
+		}
+		if toko == nil || !toko.CODAktif {
+			return fmt.Errorf("%w: toko %d does not accept cod", ErrInvalidPayment, sub.IDToko)
+		}
+		if toko.CODMaksTotal > 0 && sub.HargaTotal > toko.CODMaksTotal {
+			return fmt.Errorf("%w: toko %s only accepts cod for orders up to %d", ErrInvalidPayment, toko.NamaToko, toko.CODMaksTotal)
+		}
+	}
+	return nil
+}
+
+// HandleWebhook memproses callback provider. Callback dengan event_id yang
+// sudah pernah diproses diabaikan. Pembayaran yang masuk setelah
+// transaksinya batal langsung dikembalikan.
+func (s *paymentServiceImpl) HandleWebhook(code string, body []byte, signature string) error {
+	provider := s.provider(code)
+	if provider == nil {
+		return fmt.Errorf("%w: %s", ErrProviderNotFound, code)
+	}
+	callback, err := provider.VerifyCallback(body, signature)
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		return ErrInvalidSignature
+	}
+	if errors.Is(err, payment.ErrInvalidCallback) {
+		return fmt.Errorf("%w: body is not a valid %s callback", ErrInvalidCallback, provider.Code())
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateCallback(callback); err != nil {
+		return err
+	}
+
+	record, err := s.paymentRepo.FindByReference(provider.Code(), callback.Reference)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return fmt.Errorf("%w: reference %q", ErrPaymentNotFound, callback.Reference)
+	}
+	if callback.Status == payment.StatusPaid && callback.Jumlah != record.Jumlah {
+		return fmt.Errorf("%w: jumlah %d does not match payment amount %d", ErrInvalidCallback, callback.Jumlah, record.Jumlah)
+	}
+
+	paid, err := s.paymentRepo.ApplyCallback(record, &models.PaymentCallback{
+		Provider: provider.Code(),
+		EventID:  callback.EventID,
+		Status:   callback.Status,
+		Jumlah:   callback.Jumlah,
+	})
+	switch {
+	case errors.Is(err, payment_repository.ErrDuplicateCallback):
+		return nil
+	case errors.Is(err, payment_repository.ErrTrxClosed):
+		log.Printf("Pembayaran %s masuk setelah transaksi %d batal, dana dikembalikan", record.Reference, record.IDTrx)
+		if err := s.settleRefund(record.ID); err != nil {
+			log.Printf("Refund pembayaran %s gagal, akan dicoba ulang: %v", record.Reference, err)
+		}
+		return nil
+	case err != nil:
+		return err
+	}
+	if paid {
+		s.notifyPaid(record)
+	}
+	return nil
+}
+
+func validateCallback(callback *payment.Callback) error {
+	switch {
+	case strings.TrimSpace(callback.EventID) == "" || strings.TrimSpace(callback.Reference) == "":
+		return fmt.Errorf("%w: event_id and reference are required", ErrInvalidCallback)
+	case callback.Status != payment.StatusPaid && callback.Status != payment.StatusFailed:
+		return fmt.Errorf("%w: status must be %s or %s", ErrInvalidCallback, payment.StatusPaid, payment.StatusFailed)
+	case callback.Jumlah < 0:
+		return fmt.Errorf("%w: jumlah must not be negative", ErrInvalidCallback)
+	}
+	return nil
+}
+
+// SettleRefund mengirim refund tertunda pembayaran transaksi trxID ke
+// provider. Refund yang gagal tetap tertunda dan dicoba ulang oleh
+// RetryRefunds.
+func (s *paymentServiceImpl) SettleRefund(trxID uint) error {
+	record, err := s.paymentRepo.FindByTrxID(trxID)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.JumlahRefundTertunda <= 0 {
+		return nil
+	}
+	return s.settleRefund(record.ID)
+}
+
+// RetryRefunds mencoba ulang semua refund tertunda. Dijalankan berkala
+// oleh worker di main.
+func (s *paymentServiceImpl) RetryRefunds() error {
+	pending, err := s.paymentRepo.FindPendingRefunds()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, record := range pending {
+		if err := s.settleRefund(record.ID); err != nil {
+			errs = append(errs, fmt.Errorf("refund %s: %w", record.Reference, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *paymentServiceImpl) settleRefund(paymentID uint) error {
+	return s.paymentRepo.SettleRefund(paymentID, func(record *models.Payment, jumlah int) error {
+		provider := s.provider(record.Provider)
+		if provider == nil {
+			return fmt.Errorf("%w: %s", ErrProviderNotFound, record.Provider)
+		}
+		return provider.Refund(record.Reference, jumlah)
+	})
+}
+
+func (s *paymentServiceImpl) notifyPaid(record *models.Payment) {
+	err := s.notificationService.Notify(&models.Notification{
+		IDUser: record.Trx.IDUser,
+		Tipe:   models.NotificationOrderUpdate,
+		Judul:  "Pembayaran diterima",
+		Pesan:  fmt.Sprintf("Pembayaran pesanan %s sudah kami terima.", record.Trx.KodeInvoice),
+	})
+	if err != nil {
+		log.Printf("Gagal menyimpan notifikasi pembayaran %s: %v", record.Reference, err)
+	}
+}
@@ -2,104 +2,458 @@ package product_service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"mime/multipart"
+	"strconv"
 	"test-rakamin/internal/models"
 	product_repository "test-rakamin/internal/repository/product"
 	product_photo_repository "test-rakamin/internal/repository/product_photo"
-	"test-rakamin/utils"
+	stock_movement_repository "test-rakamin/internal/repository/stock_movement"
+	stock_reservation_repository "test-rakamin/internal/repository/stock_reservation"
+	toko_repository "test-rakamin/internal/repository/toko"
+	wishlist_repository "test-rakamin/internal/repository/wishlist"
+	category_service "test-rakamin/internal/service/category"
+	category_attribute_service "test-rakamin/internal/service/category_attribute"
+	discount_service "test-rakamin/internal/service/discount"
+	stock_alert_service "test-rakamin/internal/service/stock_alert"
+	upload_service "test-rakamin/internal/service/upload"
+	"time"
+)
+
+var (
+	ErrInvalidStatus = errors.New("invalid product status")
+	ErrForbidden     = errors.New("product does not belong to your toko")
 )
 
 type ProductService interface {
-	GetAllProducts(nama, categoryID, tokoID, minHarga, maxHarga string) ([]models.Product, error)
-	GetProductByID(id uint) (*models.Product, error)
-	CreateProduct(product *models.Product, photos []*multipart.FileHeader) (*models.Product, error)
-	UpdateProduct(id uint, updatedProduct *models.Product, photos []*multipart.FileHeader) (*models.Product, error)
-	DeleteProduct(id uint) error
+	GetAllProducts(filter product_repository.ProductFilter) ([]models.Product, error)
+	GetProductByID(id, userID uint) (*models.Product, error)
+	GetProductsByIDs(ids []uint) ([]models.Product, error)
+	GetMyProducts(userID uint, status string) ([]models.Product, error)
+	CreateProduct(userID uint, product *models.Product, atribut string, photos []*multipart.FileHeader) (*models.Product, error)
+	ImportProduct(userID uint, product *models.Product, atribut string, photoSources []string) (*models.Product, error)
+	UpdateProduct(userID, id uint, updatedProduct *models.Product, atribut string, photos []*multipart.FileHeader) (*models.Product, error)
+	DeleteProduct(userID, id uint) error
+	ApplySchedules() error
 }
 
 type productServiceImpl struct {
 	productRepo      product_repository.ProductRepository
 	productPhotoRepo product_photo_repository.ProductPhotoRepository
+	stockRepo        stock_movement_repository.StockMovementRepository
+	reservationRepo  stock_reservation_repository.StockReservationRepository
+	wishlistRepo     wishlist_repository.WishlistRepository
+	tokoRepo         toko_repository.TokoRepository
+	uploadService    upload_service.UploadService
+	stockAlert       stock_alert_service.StockAlertService
+	categoryService  category_service.CategoryService
+	attributeService category_attribute_service.CategoryAttributeService
+	discountService  discount_service.DiscountService
 }
 
-func NewProductService(repo product_repository.ProductRepository, photoRepo product_photo_repository.ProductPhotoRepository) ProductService {
-	return &productServiceImpl{productRepo: repo, productPhotoRepo: photoRepo}
+func NewProductService(repo product_repository.ProductRepository, photoRepo product_photo_repository.ProductPhotoRepository, stockRepo stock_movement_repository.StockMovementRepository, reservationRepo stock_reservation_repository.StockReservationRepository, wishlistRepo wishlist_repository.WishlistRepository, tokoRepo toko_repository.TokoRepository, uploadService upload_service.UploadService, stockAlert stock_alert_service.StockAlertService, categoryService category_service.CategoryService, attributeService category_attribute_service.CategoryAttributeService, discountService discount_service.DiscountService) ProductService {
+	return &productServiceImpl{productRepo: repo, productPhotoRepo: photoRepo, stockRepo: stockRepo, reservationRepo: reservationRepo, wishlistRepo: wishlistRepo, tokoRepo: tokoRepo, uploadService: uploadService, stockAlert: stockAlert, categoryService: categoryService, attributeService: attributeService, discountService: discountService}
 }
 
-func (s *productServiceImpl) GetAllProducts(nama, categoryID, tokoID, minHarga, maxHarga string) ([]models.Product, error) {
-	return s.productRepo.FindAllWithFilter(nama, categoryID, tokoID, minHarga, maxHarga)
+// GetAllProducts memfilter category_id beserta seluruh kategori turunannya.
+func (s *productServiceImpl) GetAllProducts(filter product_repository.ProductFilter) ([]models.Product, error) {
+	if categoryID, err := strconv.ParseUint(filter.CategoryID, 10, 32); err == nil {
+		if filter.CategoryIDs, err = s.categoryService.GetDescendantIDs(uint(categoryID)); err != nil {
+			return nil, err
+		}
+	}
+	products, err := s.productRepo.FindAllWithFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*models.Product, len(products))
+	for i := range products {
+		s.fillPhotoVariants(&products[i])
+		refs[i] = &products[i]
+	}
+	if err := s.fillStokTersedia(refs...); err != nil {
+		return nil, err
+	}
+	if err := s.discountService.FillEffectivePrice(refs...); err != nil {
+		return nil, err
+	}
+	return products, nil
 }
 
-func (s *productServiceImpl) GetProductByID(id uint) (*models.Product, error) {
+// GetProductByID mengisi IsWishlisted bila userID tidak 0, yaitu saat
+// produk dilihat oleh user yang login. Produk yang belum atau tidak lagi
+// published hanya bisa dilihat pemilik tokonya.
+func (s *productServiceImpl) GetProductByID(id, userID uint) (*models.Product, error) {
 	product, err := s.productRepo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
-	if product == nil {
+	if product == nil || (product.Status != models.ProductPublished && (userID == 0 || product.Toko.IDUser != userID)) {
 		return nil, errors.New("product not found")
 	}
+	s.fillPhotoVariants(product)
+	if err := s.fillStokTersedia(product); err != nil {
+		return nil, err
+	}
+	if err := s.discountService.FillEffectivePrice(product); err != nil {
+		return nil, err
+	}
+	if product.Breadcrumbs, err = s.categoryService.GetBreadcrumbs(product.IDCategory); err != nil {
+		return nil, err
+	}
+	if userID != 0 {
+		if product.IsWishlisted, err = s.wishlistRepo.Exists(userID, id); err != nil {
+			return nil, err
+		}
+	}
 	return product, nil
 }
 
-func (s *productServiceImpl) CreateProduct(product *models.Product, photos []*multipart.FileHeader) (*models.Product, error) {
-	err := s.productRepo.Create(product)
+func (s *productServiceImpl) GetProductsByIDs(ids []uint) ([]models.Product, error) {
+	products, err := s.productRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*models.Product, len(products))
+	for i := range products {
+		s.fillPhotoVariants(&products[i])
+		refs[i] = &products[i]
+	}
+	if err := s.fillStokTersedia(refs...); err != nil {
+		return nil, err
+	}
+	if err := s.discountService.FillEffectivePrice(refs...); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetMyProducts mengembalikan semua produk toko milik userID apa pun
+// statusnya, atau hanya yang berstatus status bila diisi.
+func (s *productServiceImpl) GetMyProducts(userID uint, status string) ([]models.Product, error) {
+	toko, err := s.tokoRepo.FindByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
+	if toko == nil {
+		return nil, errors.New("toko not found")
+	}
+	return s.GetAllProducts(product_repository.ProductFilter{
+		TokoID: strconv.FormatUint(uint64(toko.ID), 10),
+		Status: status,
+	})
+}
+
+// CreateProduct memvalidasi atribut, yaitu objek JSON kode atribut ke
+// nilai, terhadap skema kategori produk.
+func (s *productServiceImpl) CreateProduct(userID uint, product *models.Product, atribut string, photos []*multipart.FileHeader) (*models.Product, error) {
+	if err := s.validateNewProduct(product, atribut); err != nil {
+		return nil, err
+	}
 
+	// Proses semua foto dulu supaya produk tidak terlanjur dibuat bila ada
+	// foto yang ditolak validasi.
+	photoURLs := make([]string, 0, len(photos))
 	for _, photo := range photos {
-		photoURL, err := utils.SaveUploadedFile(photo)
+		photoURL, err := s.uploadService.SaveUploadedFile(photo)
 		if err != nil {
+			s.releasePhotos(photoURLs)
 			return nil, err
 		}
+		photoURLs = append(photoURLs, photoURL)
+	}
+	return s.createProduct(userID, product, photoURLs)
+}
+
+// ImportProduct sama seperti CreateProduct, tetapi fotonya diunduh dari
+// URL. Dipakai oleh import CSV.
+func (s *productServiceImpl) ImportProduct(userID uint, product *models.Product, atribut string, photoSources []string) (*models.Product, error) {
+	if err := s.validateNewProduct(product, atribut); err != nil {
+		return nil, err
+	}
 
+	photoURLs := make([]string, 0, len(photoSources))
+	for _, source := range photoSources {
+		photoURL, err := s.uploadService.SaveImageFromURL(source)
+		if err != nil {
+			s.releasePhotos(photoURLs)
+			return nil, err
+		}
+		photoURLs = append(photoURLs, photoURL)
+	}
+	return s.createProduct(userID, product, photoURLs)
+}
+
+func validateDimensions(product *models.Product) error {
+	if product.BeratGram < 0 || product.PanjangCm < 0 || product.LebarCm < 0 || product.TinggiCm < 0 {
+		return errors.New("berat and dimensions cannot be negative")
+	}
+	return nil
+}
+
+func (s *productServiceImpl) validateNewProduct(product *models.Product, atribut string) error {
+	if product.Stok < 0 {
+		return errors.New("stok cannot be negative")
+	}
+	if product.LowStockThreshold < 0 {
+		return errors.New("low stock threshold cannot be negative")
+	}
+	if err := validateDimensions(product); err != nil {
+		return err
+	}
+	// Produk baru menjadi draft kecuali seller langsung menerbitkannya.
+	if product.Status == "" {
+		product.Status = models.ProductDraft
+	}
+	if err := validateStatus(product); err != nil {
+		return err
+	}
+	attributes, err := s.attributeService.ValidateValues(product.IDCategory, atribut)
+	if err != nil {
+		return err
+	}
+	product.Atribut = attributes
+	product.AlertStok = ""
+	// Rating hanya dihitung dari tabel review, bukan dari payload seller.
+	product.RatingAvg = 0
+	product.RatingCount = 0
+	return nil
+}
+
+// validateStatus memeriksa status dan jadwal produk. PublishAt hanya
+// berlaku untuk draft dan UnpublishAt untuk draft atau published, sehingga
+// jadwal yang tidak relevan dikosongkan.
+func validateStatus(product *models.Product) error {
+	switch product.Status {
+	case models.ProductDraft:
+	case models.ProductPublished:
+		product.PublishAt = nil
+	case models.ProductArchived:
+		product.PublishAt = nil
+		product.UnpublishAt = nil
+	default:
+		return fmt.Errorf("%w: status must be draft, published or archived", ErrInvalidStatus)
+	}
+	if product.PublishAt != nil && product.UnpublishAt != nil && !product.UnpublishAt.After(*product.PublishAt) {
+		return fmt.Errorf("%w: unpublish_at must be after publish_at", ErrInvalidStatus)
+	}
+	return nil
+}
+
+// createProduct menyimpan produk beserta foto yang sudah tersimpan di
+// storage. Foto dilepas lagi bila produk gagal dibuat.
+func (s *productServiceImpl) createProduct(userID uint, product *models.Product, photoURLs []string) (*models.Product, error) {
+	// Stok awal masuk lewat ledger agar setiap unit stok tercatat asalnya.
+	stokAwal := product.Stok
+	product.Stok = 0
+	err := s.productRepo.Create(product)
+	if err != nil {
+		s.releasePhotos(photoURLs)
+		return nil, err
+	}
+	if stokAwal > 0 {
+		movement := &models.StockMovement{
+			ProductID: product.ID,
+			Tipe:      models.StockMovementAdjustment,
+			Jumlah:    stokAwal,
+			Alasan:    "stok awal",
+			IDUser:    userID,
+		}
+		if err := s.stockRepo.Apply(movement); err != nil {
+			return nil, err
+		}
+		product.Stok = movement.StokAkhir
+	}
+
+	for i, photoURL := range photoURLs {
 		photoModel := models.ProductPhoto{
 			ProductID: product.ID,
 			URL:       photoURL,
 		}
 
 		if err := s.productPhotoRepo.Create(&photoModel); err != nil {
+			s.releasePhotos(photoURLs[i:])
 			return nil, err
 		}
+		product.ProductPhoto = append(product.ProductPhoto, photoModel)
 	}
 
+	s.fillPhotoVariants(product)
+	product.StokTersedia = product.Stok
 	return product, nil
 }
 
-func (s *productServiceImpl) UpdateProduct(id uint, updatedProduct *models.Product, photos []*multipart.FileHeader) (*models.Product, error) {
-	existingProduct, err := s.productRepo.FindByID(id)
+// UpdateProduct hanya mengganti atribut produk bila atribut tidak kosong.
+func (s *productServiceImpl) UpdateProduct(userID, id uint, updatedProduct *models.Product, atribut string, photos []*multipart.FileHeader) (*models.Product, error) {
+	existingProduct, err := s.findOwnedProduct(userID, id)
 	if err != nil {
 		return nil, err
 	}
-	if existingProduct == nil {
-		return nil, errors.New("product not found")
-	}
 
 	existingProduct.NamaProduct = updatedProduct.NamaProduct
 	existingProduct.HargaReseller = updatedProduct.HargaReseller
 	existingProduct.HargaKonsumen = updatedProduct.HargaKonsumen
-	existingProduct.Stok = updatedProduct.Stok
 	existingProduct.Deskripsi = updatedProduct.Deskripsi
+	if updatedProduct.LowStockThreshold < 0 {
+		return nil, errors.New("low stock threshold cannot be negative")
+	}
+	existingProduct.LowStockThreshold = updatedProduct.LowStockThreshold
+	if err := validateDimensions(updatedProduct); err != nil {
+		return nil, err
+	}
+	existingProduct.BeratGram = updatedProduct.BeratGram
+	existingProduct.PanjangCm = updatedProduct.PanjangCm
+	existingProduct.LebarCm = updatedProduct.LebarCm
+	existingProduct.TinggiCm = updatedProduct.TinggiCm
+	// Status dan jadwal yang tidak dikirim tetap memakai nilai lama.
+	if updatedProduct.Status != "" {
+		existingProduct.Status = updatedProduct.Status
+	}
+	if updatedProduct.PublishAt != nil {
+		existingProduct.PublishAt = updatedProduct.PublishAt
+	}
+	if updatedProduct.UnpublishAt != nil {
+		existingProduct.UnpublishAt = updatedProduct.UnpublishAt
+	}
+	if err := validateStatus(existingProduct); err != nil {
+		return nil, err
+	}
+
+	var attributes []models.ProductAttribute
+	if atribut != "" {
+		if attributes, err = s.attributeService.ValidateValues(existingProduct.IDCategory, atribut); err != nil {
+			return nil, err
+		}
+	}
 
 	err = s.productRepo.Update(existingProduct)
 	if err != nil {
 		return nil, err
 	}
+	if atribut != "" {
+		if err := s.productRepo.ReplaceAttributes(existingProduct.ID, attributes); err != nil {
+			return nil, err
+		}
+		existingProduct.Atribut = attributes
+	}
+
+	// Stok tidak ditimpa langsung, tetapi dicatat sebagai penyesuaian di
+	// ledger. Stok produk bervarian adalah total stok variannya dan diubah
+	// lewat endpoint varian.
+	if delta := updatedProduct.Stok - existingProduct.Stok; delta != 0 && len(existingProduct.ProductVariant) == 0 {
+		movement := &models.StockMovement{
+			ProductID: existingProduct.ID,
+			Tipe:      models.StockMovementAdjustment,
+			Jumlah:    delta,
+			Alasan:    "ubah stok manual",
+			IDUser:    userID,
+		}
+		if err := s.stockRepo.Apply(movement); err != nil {
+			return nil, err
+		}
+		existingProduct.Stok = movement.StokAkhir
+	}
+	s.stockAlert.Evaluate(existingProduct.ID)
+	s.fillPhotoVariants(existingProduct)
+	if err := s.fillStokTersedia(existingProduct); err != nil {
+		return nil, err
+	}
 	return existingProduct, nil
 }
 
-func (s *productServiceImpl) DeleteProduct(id uint) error {
-	product, err := s.productRepo.FindByID(id)
+func (s *productServiceImpl) DeleteProduct(userID, id uint) error {
+	product, err := s.findOwnedProduct(userID, id)
 	if err != nil {
 		return err
 	}
-	if product == nil {
-		return errors.New("product not found")
-	}
 
 	if err := s.productPhotoRepo.DeleteByProductID(id); err != nil {
 		return err
 	}
+	photoURLs := make([]string, 0, len(product.ProductPhoto))
+	for _, photo := range product.ProductPhoto {
+		photoURLs = append(photoURLs, photo.URL)
+	}
+	s.releasePhotos(photoURLs)
 
 	return s.productRepo.Delete(id)
 }
+
+// findOwnedProduct mengembalikan produk id bila produk tersebut milik toko
+// userID.
+func (s *productServiceImpl) findOwnedProduct(userID, id uint) (*models.Product, error) {
+	product, err := s.productRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil || toko.ID != product.IDToko {
+		return nil, ErrForbidden
+	}
+	return product, nil
+}
+
+// ApplySchedules menerbitkan dan mengarsipkan produk yang jadwalnya sudah
+// tiba. Dijalankan berkala oleh scheduler di main.
+func (s *productServiceImpl) ApplySchedules() error {
+	now := time.Now()
+	published, err := s.productRepo.PublishDue(now)
+	if err != nil {
+		return err
+	}
+	archived, err := s.productRepo.UnpublishDue(now)
+	if err != nil {
+		return err
+	}
+	if published > 0 || archived > 0 {
+		log.Printf("Scheduler produk: %d produk diterbitkan, %d produk diarsipkan", published, archived)
+	}
+	return nil
+}
+
+func (s *productServiceImpl) releasePhotos(keys []string) {
+	for _, key := range keys {
+		if err := s.uploadService.Release(key); err != nil {
+			log.Printf("failed to release photo %s: %v", key, err)
+		}
+	}
+}
+
+func (s *productServiceImpl) fillPhotoVariants(product *models.Product) {
+	for i := range product.ProductPhoto {
+		product.ProductPhoto[i].Variants = s.uploadService.Variants(product.ProductPhoto[i].URL)
+	}
+	for i := range product.ProductVariant {
+		product.ProductVariant[i].FotoVariants = s.uploadService.Variants(product.ProductVariant[i].URLFoto)
+	}
+	product.Toko.FotoTokoVariants = s.uploadService.Variants(product.Toko.URLFotoToko)
+}
+
+// fillStokTersedia mengisi StokTersedia produk dan variannya dari stok
+// dikurangi reservasi checkout yang masih aktif.
+func (s *productServiceImpl) fillStokTersedia(products ...*models.Product) error {
+	ids := make([]uint, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	reserved, err := s.reservationRepo.ReservedTotals(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		product.StokTersedia = max(product.Stok-reserved.Product[product.ID], 0)
+		for i := range product.ProductVariant {
+			variant := &product.ProductVariant[i]
+			variant.StokTersedia = max(variant.Stok-reserved.Variant[variant.ID], 0)
+		}
+	}
+	return nil
+}
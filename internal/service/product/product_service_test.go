@@ -0,0 +1,136 @@
+package product_service
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	product_photo_repository "test-rakamin/internal/repository/product_photo"
+	toko_repository "test-rakamin/internal/repository/toko"
+	category_attribute_service "test-rakamin/internal/service/category_attribute"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeAttributeService hanya mengimplementasikan ValidateValues; method
+// lain akan panic bila terpanggil.
+type fakeAttributeService struct {
+	category_attribute_service.CategoryAttributeService
+}
+
+func (fakeAttributeService) ValidateValues(categoryID uint, raw string) ([]models.ProductAttribute, error) {
+	return nil, nil
+}
+
+func TestValidateNewProductIgnoresRating(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range map[string]string{"NamaProduct": "Kaos", "Stok": "5", "ratingavg": "5", "ratingcount": "999"} {
+		w.WriteField(k, v)
+	}
+	w.Close()
+
+	var parsed models.Product
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		return c.BodyParser(&parsed)
+	})
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if _, err := app.Test(req); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.RatingAvg != 5 || parsed.RatingCount != 999 {
+		t.Fatalf("payload tidak terbaca: rating %v/%d", parsed.RatingAvg, parsed.RatingCount)
+	}
+
+	s := &productServiceImpl{attributeService: fakeAttributeService{}}
+	if err := s.validateNewProduct(&parsed, ""); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.RatingAvg != 0 || parsed.RatingCount != 0 {
+		t.Errorf("rating = %v/%d, want 0/0", parsed.RatingAvg, parsed.RatingCount)
+	}
+}
+
+// fakeProductRepo menyimpan produk di map dan mencatat id yang dihapus.
+type fakeProductRepo struct {
+	product_repository.ProductRepository
+	products map[uint]*models.Product
+	deleted  []uint
+}
+
+func (r *fakeProductRepo) FindByID(id uint) (*models.Product, error) {
+	return r.products[id], nil
+}
+
+func (r *fakeProductRepo) Delete(id uint) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+type fakeTokoRepo struct {
+	toko_repository.TokoRepository
+	byUser map[uint]*models.Toko
+}
+
+func (r fakeTokoRepo) FindByUserID(userID uint) (*models.Toko, error) {
+	return r.byUser[userID], nil
+}
+
+type fakePhotoRepo struct {
+	product_photo_repository.ProductPhotoRepository
+}
+
+func (fakePhotoRepo) DeleteByProductID(productID uint) error {
+	return nil
+}
+
+func TestProductOwnership(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		wantErr error
+	}{
+		{"pemilik toko", 1, nil},
+		{"seller toko lain", 2, ErrForbidden},
+		{"user tanpa toko", 3, ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			productRepo := &fakeProductRepo{products: map[uint]*models.Product{
+				10: {ID: 10, IDToko: 1, NamaProduct: "Kaos"},
+			}}
+			s := &productServiceImpl{
+				productRepo:      productRepo,
+				productPhotoRepo: fakePhotoRepo{},
+				tokoRepo: fakeTokoRepo{byUser: map[uint]*models.Toko{
+					1: {ID: 1, IDUser: 1},
+					2: {ID: 2, IDUser: 2},
+				}},
+			}
+
+			if tt.wantErr != nil {
+				_, err := s.UpdateProduct(tt.userID, 10, &models.Product{NamaProduct: "Diganti"}, "", nil)
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("UpdateProduct err = %v, want %v", err, tt.wantErr)
+				}
+				if got := productRepo.products[10].NamaProduct; got != "Kaos" {
+					t.Errorf("NamaProduct = %q, produk tidak boleh berubah", got)
+				}
+			}
+
+			err := s.DeleteProduct(tt.userID, 10)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteProduct err = %v, want %v", err, tt.wantErr)
+			}
+			if deleted := len(productRepo.deleted) > 0; deleted != (tt.wantErr == nil) {
+				t.Errorf("produk terhapus = %v, want %v", deleted, tt.wantErr == nil)
+			}
+		})
+	}
+}
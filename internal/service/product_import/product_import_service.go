@@ -0,0 +1,358 @@
+package product_import_service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"test-rakamin/internal/models"
+	category_repository "test-rakamin/internal/repository/category"
+	import_job_repository "test-rakamin/internal/repository/import_job"
+	product_repository "test-rakamin/internal/repository/product"
+	toko_repository "test-rakamin/internal/repository/toko"
+	product_service "test-rakamin/internal/service/product"
+	upload_service "test-rakamin/internal/service/upload"
+)
+
+var ErrInvalidCSV = errors.New("invalid csv file")
+
+// csvHeader adalah kolom file import sekaligus urutan kolom hasil export.
+// Beberapa URL foto dipisahkan dengan "|", atribut ditulis sebagai objek
+// JSON kode atribut ke nilai, dan status kosong berarti draft.
+var csvHeader = []string{"nama_product", "category", "harga_reseller", "harga_konsumen", "stok", "deskripsi", "photo_urls", "atribut", "status"}
+
+var requiredColumns = []string{"nama_product", "category", "harga_reseller", "harga_konsumen"}
+
+const (
+	maxImportRows   = 5000
+	maxImportErrors = 1000
+	// progressEvery menentukan seberapa sering progres job disimpan.
+	progressEvery = 20
+)
+
+type ProductImportService interface {
+	StartImport(userID uint, fileName string, file io.Reader, dryRun bool) (*models.ImportJob, error)
+	GetImportJob(userID, id uint) (*models.ImportJob, error)
+	ExportProducts(userID uint, baseURL string, w io.Writer) error
+	FailInterruptedJobs() error
+}
+
+type productImportServiceImpl struct {
+	jobRepo        import_job_repository.ImportJobRepository
+	productRepo    product_repository.ProductRepository
+	categoryRepo   category_repository.CategoryRepository
+	tokoRepo       toko_repository.TokoRepository
+	productService product_service.ProductService
+	uploadService  upload_service.UploadService
+}
+
+func NewProductImportService(jobRepo import_job_repository.ImportJobRepository, productRepo product_repository.ProductRepository, categoryRepo category_repository.CategoryRepository, tokoRepo toko_repository.TokoRepository, productService product_service.ProductService, uploadService upload_service.UploadService) ProductImportService {
+	return &productImportServiceImpl{jobRepo: jobRepo, productRepo: productRepo, categoryRepo: categoryRepo, tokoRepo: tokoRepo, productService: productService, uploadService: uploadService}
+}
+
+// csvRow adalah satu baris data CSV beserta nomor barisnya di file.
+type csvRow struct {
+	line   int
+	fields map[string]string
+}
+
+// StartImport membaca dan memeriksa header CSV lalu memproses setiap baris
+// di background. Progres dan error per baris bisa dipantau lewat
+// GetImportJob.
+func (s *productImportServiceImpl) StartImport(userID uint, fileName string, file io.Reader, dryRun bool) (*models.ImportJob, error) {
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil {
+		return nil, errors.New("toko not found")
+	}
+
+	rows, err := readCSV(file)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ImportJob{
+		IDUser:    userID,
+		IDToko:    toko.ID,
+		NamaFile:  fileName,
+		Status:    models.ImportJobPending,
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Errors:    []models.ImportRowError{},
+	}
+	if err := s.jobRepo.Create(job); err != nil {
+		return nil, err
+	}
+
+	go s.runImport(*job, rows)
+	return job, nil
+}
+
+func readCSV(file io.Reader) ([]csvRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %v", ErrInvalidCSV, err)
+	}
+	columns := make([]string, len(header))
+	present := map[string]bool{}
+	for i, name := range header {
+		if i == 0 {
+			// File dari Excel sering diawali BOM UTF-8.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[i] = strings.ToLower(strings.TrimSpace(name))
+		present[columns[i]] = true
+	}
+	for _, name := range requiredColumns {
+		if !present[name] {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidCSV, name)
+		}
+	}
+
+	var rows []csvRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := csvRow{line: line, fields: map[string]string{}}
+		empty := true
+		for i, value := range record {
+			if i < len(columns) {
+				row.fields[columns[i]] = strings.TrimSpace(value)
+				empty = empty && row.fields[columns[i]] == ""
+			}
+		}
+		if empty {
+			continue
+		}
+		rows = append(rows, row)
+		if len(rows) > maxImportRows {
+			return nil, fmt.Errorf("%w: at most %d rows per import", ErrInvalidCSV, maxImportRows)
+		}
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no product rows", ErrInvalidCSV)
+	}
+	return rows, nil
+}
+
+func (s *productImportServiceImpl) runImport(job models.ImportJob, rows []csvRow) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Import job %d panic: %v", job.ID, r)
+			s.finishJob(&job, models.ImportJobFailed, fmt.Sprintf("import stopped unexpectedly: %v", r))
+		}
+	}()
+
+	now := time.Now()
+	job.Status = models.ImportJobRunning
+	job.StartedAt = &now
+	s.saveProgress(&job)
+
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		s.finishJob(&job, models.ImportJobFailed, err.Error())
+		return
+	}
+	categoryIDs := map[string]uint{}
+	for _, category := range categories {
+		categoryIDs[strings.ToLower(category.NamaCategory)] = category.ID
+		categoryIDs[strconv.FormatUint(uint64(category.ID), 10)] = category.ID
+	}
+
+	for i, row := range rows {
+		product, photoURLs, err := parseRow(row, categoryIDs)
+		if err == nil && !job.DryRun {
+			product.IDToko = job.IDToko
+			_, err = s.productService.ImportProduct(job.IDUser, product, row.fields["atribut"], photoURLs)
+		}
+
+		if err != nil {
+			job.FailedRows++
+			if len(job.Errors) < maxImportErrors {
+				job.Errors = append(job.Errors, models.ImportRowError{Row: row.line, Message: err.Error()})
+			}
+		} else {
+			job.SuccessRows++
+		}
+		if (i+1)%progressEvery == 0 {
+			s.saveProgress(&job)
+		}
+	}
+
+	message := fmt.Sprintf("%d of %d rows imported", job.SuccessRows, job.TotalRows)
+	if job.DryRun {
+		message = fmt.Sprintf("dry run: %d of %d rows valid", job.SuccessRows, job.TotalRows)
+	}
+	s.finishJob(&job, models.ImportJobCompleted, message)
+}
+
+func parseRow(row csvRow, categoryIDs map[string]uint) (*models.Product, []string, error) {
+	product := &models.Product{
+		NamaProduct: row.fields["nama_product"],
+		Deskripsi:   row.fields["deskripsi"],
+		Status:      strings.ToLower(row.fields["status"]),
+	}
+	if product.NamaProduct == "" {
+		return nil, nil, errors.New("nama_product is required")
+	}
+
+	category := row.fields["category"]
+	categoryID, ok := categoryIDs[strings.ToLower(category)]
+	if !ok {
+		return nil, nil, fmt.Errorf("category %q not found", category)
+	}
+	product.IDCategory = categoryID
+
+	var err error
+	if product.HargaReseller, err = parseAmount(row.fields, "harga_reseller", true); err != nil {
+		return nil, nil, err
+	}
+	if product.HargaKonsumen, err = parseAmount(row.fields, "harga_konsumen", true); err != nil {
+		return nil, nil, err
+	}
+	if product.Stok, err = parseAmount(row.fields, "stok", false); err != nil {
+		return nil, nil, err
+	}
+
+	var photoURLs []string
+	for _, photoURL := range strings.Split(row.fields["photo_urls"], "|") {
+		photoURL = strings.TrimSpace(photoURL)
+		if photoURL == "" {
+			continue
+		}
+		if !strings.HasPrefix(photoURL, "http://") && !strings.HasPrefix(photoURL, "https://") {
+			return nil, nil, fmt.Errorf("photo url %q must be http or https", photoURL)
+		}
+		photoURLs = append(photoURLs, photoURL)
+	}
+	return product, photoURLs, nil
+}
+
+func parseAmount(fields map[string]string, column string, required bool) (int, error) {
+	value := fields[column]
+	if value == "" {
+		if required {
+			return 0, fmt.Errorf("%s is required", column)
+		}
+		return 0, nil
+	}
+	amount, err := strconv.Atoi(value)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative whole number", column)
+	}
+	return amount, nil
+}
+
+func (s *productImportServiceImpl) saveProgress(job *models.ImportJob) {
+	if err := s.jobRepo.Update(job); err != nil {
+		log.Printf("Gagal menyimpan progres import job %d: %v", job.ID, err)
+	}
+}
+
+func (s *productImportServiceImpl) finishJob(job *models.ImportJob, status, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Message = message
+	job.FinishedAt = &now
+	s.saveProgress(job)
+}
+
+func (s *productImportServiceImpl) GetImportJob(userID, id uint) (*models.ImportJob, error) {
+	job, err := s.jobRepo.FindByIDAndUserID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, errors.New("import job not found")
+	}
+	return job, nil
+}
+
+// ExportProducts menulis produk toko milik userID dengan format yang sama
+// seperti file import. URL foto yang relatif dilengkapi dengan baseURL
+// supaya file hasil export bisa langsung diimport ulang.
+func (s *productImportServiceImpl) ExportProducts(userID uint, baseURL string, w io.Writer) error {
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if toko == nil {
+		return errors.New("toko not found")
+	}
+	products, err := s.productRepo.FindByTokoID(toko.ID)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, product := range products {
+		photoURLs := make([]string, 0, len(product.ProductPhoto))
+		for _, photo := range product.ProductPhoto {
+			photoURL := s.uploadService.Variants(photo.URL)["original"]
+			if strings.HasPrefix(photoURL, "/") {
+				photoURL = strings.TrimSuffix(baseURL, "/") + photoURL
+			}
+			photoURLs = append(photoURLs, photoURL)
+		}
+
+		atribut := ""
+		if len(product.Atribut) > 0 {
+			values := make(map[string]string, len(product.Atribut))
+			for _, attribute := range product.Atribut {
+				values[attribute.Kode] = attribute.Nilai
+			}
+			encoded, err := json.Marshal(values)
+			if err != nil {
+				return err
+			}
+			atribut = string(encoded)
+		}
+
+		err := writer.Write([]string{
+			product.NamaProduct,
+			product.Category.NamaCategory,
+			strconv.Itoa(product.HargaReseller),
+			strconv.Itoa(product.HargaKonsumen),
+			strconv.Itoa(product.Stok),
+			product.Deskripsi,
+			strings.Join(photoURLs, "|"),
+			atribut,
+			product.Status,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *productImportServiceImpl) FailInterruptedJobs() error {
+	count, err := s.jobRepo.FailUnfinished("import interrupted by server restart")
+	if count > 0 {
+		log.Printf("Menandai %d import job yang terhenti sebagai gagal", count)
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package product_price_tier_service
+
+import (
+	"errors"
+	"fmt"
+
+	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	product_price_tier_repository "test-rakamin/internal/repository/product_price_tier"
+	toko_repository "test-rakamin/internal/repository/toko"
+)
+
+var ErrForbidden = errors.New("product does not belong to your toko")
+
+type ProductPriceTierService interface {
+	GetPriceTiers(productID uint) ([]models.ProductPriceTier, error)
+	CreatePriceTier(userID, productID uint, payload *models.ProductPriceTierPayload) (*models.ProductPriceTier, error)
+	DeletePriceTier(userID, productID, id uint) error
+}
+
+type productPriceTierServiceImpl struct {
+	priceTierRepo product_price_tier_repository.ProductPriceTierRepository
+	productRepo   product_repository.ProductRepository
+	tokoRepo      toko_repository.TokoRepository
+}
+
+func NewProductPriceTierService(repo product_price_tier_repository.ProductPriceTierRepository, productRepo product_repository.ProductRepository, tokoRepo toko_repository.TokoRepository) ProductPriceTierService {
+	return &productPriceTierServiceImpl{priceTierRepo: repo, productRepo: productRepo, tokoRepo: tokoRepo}
+}
+
+func (s *productPriceTierServiceImpl) GetPriceTiers(productID uint) ([]models.ProductPriceTier, error) {
+	return s.priceTierRepo.FindByProductID(productID)
+}
+
+func (s *productPriceTierServiceImpl) CreatePriceTier(userID, productID uint, payload *models.ProductPriceTierPayload) (*models.ProductPriceTier, error) {
+	if _, err := s.findOwnedProduct(userID, productID); err != nil {
+		return nil, err
+	}
+	if payload.MinKuantitas < 2 {
+		return nil, errors.New("min_kuantitas must be at least 2")
+	}
+	if payload.Harga <= 0 {
+		return nil, errors.New("harga must be positive")
+	}
+
+	existing, err := s.priceTierRepo.FindByMinKuantitas(productID, payload.MinKuantitas)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("price tier for min_kuantitas %d already exists", payload.MinKuantitas)
+	}
+
+	tier := &models.ProductPriceTier{
+		ProductID:    productID,
+		MinKuantitas: payload.MinKuantitas,
+		Harga:        payload.Harga,
+	}
+	if err := s.priceTierRepo.Create(tier); err != nil {
+		return nil, err
+	}
+	return tier, nil
+}
+
+func (s *productPriceTierServiceImpl) DeletePriceTier(userID, productID, id uint) error {
+	if _, err := s.findOwnedProduct(userID, productID); err != nil {
+		return err
+	}
+	tier, err := s.priceTierRepo.FindByID(productID, id)
+	if err != nil {
+		return err
+	}
+	if tier == nil {
+		return errors.New("price tier not found")
+	}
+	return s.priceTierRepo.Delete(id)
+}
+
+func (s *productPriceTierServiceImpl) findOwnedProduct(userID, productID uint) (*models.Product, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil || toko.ID != product.IDToko {
+		return nil, ErrForbidden
+	}
+	return product, nil
+}
@@ -0,0 +1,296 @@
+package product_variant_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"strings"
+
+	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	product_variant_repository "test-rakamin/internal/repository/product_variant"
+	stock_movement_repository "test-rakamin/internal/repository/stock_movement"
+	stock_reservation_repository "test-rakamin/internal/repository/stock_reservation"
+	toko_repository "test-rakamin/internal/repository/toko"
+	stock_alert_service "test-rakamin/internal/service/stock_alert"
+	upload_service "test-rakamin/internal/service/upload"
+)
+
+var ErrForbidden = errors.New("product does not belong to your toko")
+
+type ProductVariantService interface {
+	GetVariants(productID uint) ([]models.ProductVariant, error)
+	GetVariantByID(productID, id uint) (*models.ProductVariant, error)
+	CreateVariant(userID, productID uint, payload *models.ProductVariantPayload, photo *multipart.FileHeader) (*models.ProductVariant, error)
+	UpdateVariant(userID, productID, id uint, payload *models.ProductVariantPayload, photo *multipart.FileHeader) (*models.ProductVariant, error)
+	DeleteVariant(userID, productID, id uint) error
+}
+
+type productVariantServiceImpl struct {
+	variantRepo     product_variant_repository.ProductVariantRepository
+	productRepo     product_repository.ProductRepository
+	tokoRepo        toko_repository.TokoRepository
+	stockRepo       stock_movement_repository.StockMovementRepository
+	reservationRepo stock_reservation_repository.StockReservationRepository
+	uploadService   upload_service.UploadService
+	stockAlert      stock_alert_service.StockAlertService
+}
+
+func NewProductVariantService(repo product_variant_repository.ProductVariantRepository, productRepo product_repository.ProductRepository, tokoRepo toko_repository.TokoRepository, stockRepo stock_movement_repository.StockMovementRepository, reservationRepo stock_reservation_repository.StockReservationRepository, uploadService upload_service.UploadService, stockAlert stock_alert_service.StockAlertService) ProductVariantService {
+	return &productVariantServiceImpl{variantRepo: repo, productRepo: productRepo, tokoRepo: tokoRepo, stockRepo: stockRepo, reservationRepo: reservationRepo, uploadService: uploadService, stockAlert: stockAlert}
+}
+
+func (s *productVariantServiceImpl) GetVariants(productID uint) ([]models.ProductVariant, error) {
+	variants, err := s.variantRepo.FindByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]*models.ProductVariant, len(variants))
+	for i := range variants {
+		variants[i].FotoVariants = s.uploadService.Variants(variants[i].URLFoto)
+		refs[i] = &variants[i]
+	}
+	if err := s.fillStokTersedia(productID, refs...); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+func (s *productVariantServiceImpl) GetVariantByID(productID, id uint) (*models.ProductVariant, error) {
+	variant, err := s.variantRepo.FindByID(productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, errors.New("variant not found")
+	}
+	variant.FotoVariants = s.uploadService.Variants(variant.URLFoto)
+	if err := s.fillStokTersedia(productID, variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (s *productVariantServiceImpl) CreateVariant(userID, productID uint, payload *models.ProductVariantPayload, photo *multipart.FileHeader) (*models.ProductVariant, error) {
+	product, err := s.findOwnedProduct(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reservasi checkout produk tanpa varian tidak bisa dipindah ke varian,
+	// jadi varian pertama baru boleh dibuat setelah reservasinya selesai.
+	if len(product.ProductVariant) == 0 {
+		reserved, err := s.reservationRepo.ReservedTotals([]uint{productID})
+		if err != nil {
+			return nil, err
+		}
+		if reserved.Product[productID] > 0 {
+			return nil, errors.New("product still has unpaid orders holding its stock")
+		}
+	}
+
+	variant := &models.ProductVariant{ProductID: productID}
+	if err := s.applyPayload(variant, payload); err != nil {
+		return nil, err
+	}
+
+	if photo != nil {
+		photoURL, err := s.uploadService.SaveUploadedFile(photo)
+		if err != nil {
+			return nil, err
+		}
+		variant.URLFoto = photoURL
+	}
+
+	if err := s.variantRepo.Create(variant); err != nil {
+		if err := s.uploadService.Release(variant.URLFoto); err != nil {
+			log.Printf("failed to release variant photo %s: %v", variant.URLFoto, err)
+		}
+		return nil, err
+	}
+
+	// Saat varian pertama dibuat, stok produk tanpa varian dikeluarkan dulu
+	// karena setelah ini stok produk adalah total stok variannya.
+	if len(product.ProductVariant) == 0 && product.Stok != 0 {
+		if err := s.stockRepo.Apply(&models.StockMovement{
+			ProductID: productID,
+			Tipe:      models.StockMovementAdjustment,
+			Jumlah:    -product.Stok,
+			Alasan:    "stok dipindah ke varian",
+			IDUser:    userID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.adjustStok(userID, variant, payload.Stok, "stok awal varian"); err != nil {
+		return nil, err
+	}
+
+	variant.FotoVariants = s.uploadService.Variants(variant.URLFoto)
+	if err := s.fillStokTersedia(productID, variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (s *productVariantServiceImpl) UpdateVariant(userID, productID, id uint, payload *models.ProductVariantPayload, photo *multipart.FileHeader) (*models.ProductVariant, error) {
+	if _, err := s.findOwnedProduct(userID, productID); err != nil {
+		return nil, err
+	}
+
+	variant, err := s.variantRepo.FindByID(productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, errors.New("variant not found")
+	}
+	if err := s.applyPayload(variant, payload); err != nil {
+		return nil, err
+	}
+
+	oldPhotoURL := variant.URLFoto
+	if photo != nil {
+		photoURL, err := s.uploadService.SaveUploadedFile(photo)
+		if err != nil {
+			return nil, err
+		}
+		variant.URLFoto = photoURL
+	}
+
+	if err := s.variantRepo.Update(variant); err != nil {
+		if photo != nil {
+			if err := s.uploadService.Release(variant.URLFoto); err != nil {
+				log.Printf("failed to release variant photo %s: %v", variant.URLFoto, err)
+			}
+		}
+		return nil, err
+	}
+	if photo != nil && oldPhotoURL != "" {
+		if err := s.uploadService.Release(oldPhotoURL); err != nil {
+			log.Printf("failed to release variant photo %s: %v", oldPhotoURL, err)
+		}
+	}
+	if err := s.adjustStok(userID, variant, payload.Stok, "ubah stok varian manual"); err != nil {
+		return nil, err
+	}
+
+	variant.FotoVariants = s.uploadService.Variants(variant.URLFoto)
+	if err := s.fillStokTersedia(productID, variant); err != nil {
+		return nil, err
+	}
+	return variant, nil
+}
+
+func (s *productVariantServiceImpl) DeleteVariant(userID, productID, id uint) error {
+	if _, err := s.findOwnedProduct(userID, productID); err != nil {
+		return err
+	}
+
+	variant, err := s.variantRepo.FindByID(productID, id)
+	if err != nil {
+		return err
+	}
+	if variant == nil {
+		return errors.New("variant not found")
+	}
+
+	if err := s.adjustStok(userID, variant, 0, "varian dihapus"); err != nil {
+		return err
+	}
+	if err := s.variantRepo.Delete(id); err != nil {
+		return err
+	}
+	if err := s.uploadService.Release(variant.URLFoto); err != nil {
+		log.Printf("failed to release variant photo %s: %v", variant.URLFoto, err)
+	}
+	return nil
+}
+
+// adjustStok mencatat selisih antara stok varian saat ini dan stok yang
+// diminta sebagai penyesuaian di ledger.
+func (s *productVariantServiceImpl) adjustStok(userID uint, variant *models.ProductVariant, stok int, alasan string) error {
+	delta := stok - variant.Stok
+	if delta == 0 {
+		return nil
+	}
+	variantID := variant.ID
+	movement := &models.StockMovement{
+		ProductID: variant.ProductID,
+		VariantID: &variantID,
+		Tipe:      models.StockMovementAdjustment,
+		Jumlah:    delta,
+		Alasan:    alasan,
+		IDUser:    userID,
+	}
+	if err := s.stockRepo.Apply(movement); err != nil {
+		return err
+	}
+	variant.Stok = movement.StokAkhir
+	s.stockAlert.Evaluate(variant.ProductID)
+	return nil
+}
+
+func (s *productVariantServiceImpl) findOwnedProduct(userID, productID uint) (*models.Product, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil || toko.ID != product.IDToko {
+		return nil, ErrForbidden
+	}
+	return product, nil
+}
+
+func (s *productVariantServiceImpl) applyPayload(variant *models.ProductVariant, payload *models.ProductVariantPayload) error {
+	sku := strings.TrimSpace(payload.SKU)
+	if sku == "" {
+		return errors.New("sku is required")
+	}
+	existing, err := s.variantRepo.FindBySKU(variant.ProductID, sku)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != variant.ID {
+		return fmt.Errorf("sku %s already exists", sku)
+	}
+
+	var opsi map[string]string
+	if payload.Opsi != "" {
+		if err := json.Unmarshal([]byte(payload.Opsi), &opsi); err != nil {
+			return errors.New("opsi must be a JSON object of option names to values")
+		}
+	}
+	if payload.Stok < 0 {
+		return errors.New("stok cannot be negative")
+	}
+	if (payload.HargaKonsumen != nil && *payload.HargaKonsumen < 0) || (payload.HargaReseller != nil && *payload.HargaReseller < 0) {
+		return errors.New("harga cannot be negative")
+	}
+
+	variant.SKU = sku
+	variant.Opsi = opsi
+	variant.HargaKonsumen = payload.HargaKonsumen
+	variant.HargaReseller = payload.HargaReseller
+	return nil
+}
+
+func (s *productVariantServiceImpl) fillStokTersedia(productID uint, variants ...*models.ProductVariant) error {
+	reserved, err := s.reservationRepo.ReservedTotals([]uint{productID})
+	if err != nil {
+		return err
+	}
+	for _, variant := range variants {
+		variant.StokTersedia = max(variant.Stok-reserved.Variant[variant.ID], 0)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package review_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	review_repository "test-rakamin/internal/repository/review"
+	toko_repository "test-rakamin/internal/repository/toko"
+	upload_service "test-rakamin/internal/service/upload"
+)
+
+const maxReviewPhotos = 5
+
+var (
+	ErrForbidden     = errors.New("you are not allowed to modify this review")
+	ErrNotPurchased  = errors.New("only buyers with a completed purchase of this product can review it")
+	ErrInvalidReview = errors.New("invalid review")
+)
+
+type ReviewService interface {
+	GetReviews(productID uint, rating int) ([]models.Review, error)
+	CreateReview(userID, productID uint, payload *models.ReviewPayload, photos []*multipart.FileHeader) (*models.Review, error)
+	UpdateReview(userID, productID, id uint, payload *models.ReviewPayload, photos []*multipart.FileHeader) (*models.Review, error)
+	ReplyReview(userID, productID, id uint, balasan string) (*models.Review, error)
+}
+
+type reviewServiceImpl struct {
+	reviewRepo    review_repository.ReviewRepository
+	productRepo   product_repository.ProductRepository
+	tokoRepo      toko_repository.TokoRepository
+	uploadService upload_service.UploadService
+}
+
+func NewReviewService(repo review_repository.ReviewRepository, productRepo product_repository.ProductRepository, tokoRepo toko_repository.TokoRepository, uploadService upload_service.UploadService) ReviewService {
+	return &reviewServiceImpl{reviewRepo: repo, productRepo: productRepo, tokoRepo: tokoRepo, uploadService: uploadService}
+}
+
+func (s *reviewServiceImpl) GetReviews(productID uint, rating int) ([]models.Review, error) {
+	reviews, err := s.reviewRepo.FindByProductID(productID, rating)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reviews {
+		s.fillPhotoVariants(&reviews[i])
+	}
+	return reviews, nil
+}
+
+func (s *reviewServiceImpl) CreateReview(userID, productID uint, payload *models.ReviewPayload, photos []*multipart.FileHeader) (*models.Review, error) {
+	if err := validatePayload(payload, photos); err != nil {
+		return nil, err
+	}
+
+	details, err := s.reviewRepo.FindReviewableDetailTrx(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	var detail *models.DetailTrx
+	for i := range details {
+		if payload.IDDetailTrx == 0 || details[i].ID == payload.IDDetailTrx {
+			detail = &details[i]
+			break
+		}
+	}
+	if detail == nil {
+		return nil, ErrNotPurchased
+	}
+
+	photoURLs, err := s.savePhotos(photos)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &models.Review{
+		ProductID:   productID,
+		IDUser:      userID,
+		IDDetailTrx: detail.ID,
+		Rating:      payload.Rating,
+		Ulasan:      strings.TrimSpace(payload.Ulasan),
+	}
+	if err := s.reviewRepo.Create(review); err != nil {
+		s.releasePhotos(photoURLs)
+		return nil, err
+	}
+	if _, err := s.reviewRepo.ReplacePhotos(review.ID, photoURLs); err != nil {
+		s.releasePhotos(photoURLs)
+		return nil, err
+	}
+	if err := s.reviewRepo.RefreshRatings(productID); err != nil {
+		return nil, err
+	}
+
+	return s.findReview(productID, review.ID)
+}
+
+// UpdateReview mengubah rating dan ulasan. Foto hanya diganti bila ada
+// foto baru yang diunggah.
+func (s *reviewServiceImpl) UpdateReview(userID, productID, id uint, payload *models.ReviewPayload, photos []*multipart.FileHeader) (*models.Review, error) {
+	if err := validatePayload(payload, photos); err != nil {
+		return nil, err
+	}
+	review, err := s.findReview(productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.IDUser != userID {
+		return nil, ErrForbidden
+	}
+
+	review.Rating = payload.Rating
+	review.Ulasan = strings.TrimSpace(payload.Ulasan)
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, err
+	}
+
+	if len(photos) > 0 {
+		photoURLs, err := s.savePhotos(photos)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.reviewRepo.ReplacePhotos(review.ID, photoURLs); err != nil {
+			s.releasePhotos(photoURLs)
+			return nil, err
+		}
+		oldURLs := make([]string, 0, len(review.ReviewPhoto))
+		for _, photo := range review.ReviewPhoto {
+			oldURLs = append(oldURLs, photo.URL)
+		}
+		s.releasePhotos(oldURLs)
+	}
+
+	if err := s.reviewRepo.RefreshRatings(productID); err != nil {
+		return nil, err
+	}
+	return s.findReview(productID, id)
+}
+
+// ReplyReview menyimpan balasan seller. Hanya pemilik toko produk yang
+// boleh membalas.
+func (s *reviewServiceImpl) ReplyReview(userID, productID, id uint, balasan string) (*models.Review, error) {
+	balasan = strings.TrimSpace(balasan)
+	if balasan == "" {
+		return nil, fmt.Errorf("%w: balasan is required", ErrInvalidReview)
+	}
+	review, err := s.findReview(productID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil || toko == nil || product.IDToko != toko.ID {
+		return nil, ErrForbidden
+	}
+
+	now := time.Now()
+	review.Balasan = balasan
+	review.DibalasAt = &now
+	if err := s.reviewRepo.Update(review); err != nil {
+		return nil, err
+	}
+	s.fillPhotoVariants(review)
+	return review, nil
+}
+
+func validatePayload(payload *models.ReviewPayload, photos []*multipart.FileHeader) error {
+	if payload.Rating < 1 || payload.Rating > 5 {
+		return fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidReview)
+	}
+	if len(photos) > maxReviewPhotos {
+		return fmt.Errorf("%w: at most %d photos per review", ErrInvalidReview, maxReviewPhotos)
+	}
+	return nil
+}
+
+func (s *reviewServiceImpl) findReview(productID, id uint) (*models.Review, error) {
+	review, err := s.reviewRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil || review.ProductID != productID {
+		return nil, errors.New("review not found")
+	}
+	s.fillPhotoVariants(review)
+	return review, nil
+}
+
+func (s *reviewServiceImpl) savePhotos(photos []*multipart.FileHeader) ([]string, error) {
+	photoURLs := make([]string, 0, len(photos))
+	for _, photo := range photos {
+		photoURL, err := s.uploadService.SaveUploadedFile(photo)
+		if err != nil {
+			s.releasePhotos(photoURLs)
+			return nil, err
+		}
+		photoURLs = append(photoURLs, photoURL)
+	}
+	return photoURLs, nil
+}
+
+func (s *reviewServiceImpl) releasePhotos(keys []string) {
+	for _, key := range keys {
+		if err := s.uploadService.Release(key); err != nil {
+			log.Printf("failed to release review photo %s: %v", key, err)
+		}
+	}
+}
+
+func (s *reviewServiceImpl) fillPhotoVariants(review *models.Review) {
+	for i := range review.ReviewPhoto {
+		review.ReviewPhoto[i].Variants = s.uploadService.Variants(review.ReviewPhoto[i].URL)
+	}
+}
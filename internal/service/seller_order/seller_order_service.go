@@ -0,0 +1,280 @@
+package seller_order_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"test-rakamin/internal/models"
+	toko_repository "test-rakamin/internal/repository/toko"
+	trx_repository "test-rakamin/internal/repository/trx"
+	notification_service "test-rakamin/internal/service/notification"
+	payment_service "test-rakamin/internal/service/payment"
+)
+
+var (
+	ErrTokoNotFound  = errors.New("toko not found")
+	ErrOrderNotFound = errors.New("order not found")
+	ErrInvalidOrder  = errors.New("invalid order request")
+	ErrInvalidStatus = errors.New("order status does not allow this action")
+)
+
+// orderStatuses adalah status yang bisa dipakai untuk memfilter pesanan.
+var orderStatuses = []string{
+	models.TrxStatusPendingPayment,
+	models.TrxStatusPaid,
+	models.TrxStatusProcessing,
+	models.TrxStatusShipped,
+	models.TrxStatusDelivered,
+	models.TrxStatusRejected,
+	models.TrxStatusCancelled,
+}
+
+type SellerOrderService interface {
+	GetOrders(userID uint, status, from, to string) ([]models.TrxToko, error)
+	GetOrder(userID, id uint) (*models.TrxToko, error)
+	AcceptOrder(userID, id uint) (*models.TrxToko, error)
+	ShipOrder(userID, id uint, noResi string) (*models.TrxToko, error)
+	RejectOrder(userID, id uint, alasan string) (*models.TrxToko, error)
+	CollectCOD(userID, id uint) (*models.TrxToko, error)
+	GetSettlement(userID uint, from, to string) (*models.SettlementReport, error)
+}
+
+type sellerOrderServiceImpl struct {
+	trxRepo             trx_repository.TrxRepository
+	tokoRepo            toko_repository.TokoRepository
+	notificationService notification_service.NotificationService
+	paymentService      payment_service.PaymentService
+}
+
+func NewSellerOrderService(trxRepo trx_repository.TrxRepository, tokoRepo toko_repository.TokoRepository, notificationService notification_service.NotificationService, paymentService payment_service.PaymentService) SellerOrderService {
+	return &sellerOrderServiceImpl{trxRepo: trxRepo, tokoRepo: tokoRepo, notificationService: notificationService, paymentService: paymentService}
+}
+
+// GetOrders mengembalikan sub-order toko milik userID, terbaru lebih dulu.
+// from dan to berformat YYYY-MM-DD dan keduanya inklusif.
+func (s *sellerOrderServiceImpl) GetOrders(userID uint, status, from, to string) ([]models.TrxToko, error) {
+	toko, err := s.myToko(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := trx_repository.SubOrderFilter{Status: strings.ToLower(status)}
+	if filter.Status != "" && !contains(orderStatuses, filter.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalidOrder, strings.Join(orderStatuses, ", "))
+	}
+	if err := parseDateRange(&filter, from, to); err != nil {
+		return nil, err
+	}
+	return s.trxRepo.FindSubOrders(toko.ID, filter)
+}
+
+// GetSettlement merangkum pesanan toko milik userID yang sudah dibayar
+// atau sedang berjalan, dengan pesanan COD dihitung terpisah. from dan to
+// sama seperti GetOrders.
+func (s *sellerOrderServiceImpl) GetSettlement(userID uint, from, to string) (*models.SettlementReport, error) {
+	toko, err := s.myToko(userID)
+	if err != nil {
+		return nil, err
+	}
+	var filter trx_repository.SubOrderFilter
+	if err := parseDateRange(&filter, from, to); err != nil {
+		return nil, err
+	}
+	return s.trxRepo.Settlement(toko.ID, filter)
+}
+
+// parseDateRange mengisi From dan To filter. to inklusif, sedangkan
+// SubOrderFilter.To eksklusif.
+func parseDateRange(filter *trx_repository.SubOrderFilter, from, to string) error {
+	var err error
+	if filter.From, err = parseDate("from", from); err != nil {
+		return err
+	}
+	if filter.To, err = parseDate("to", to); err != nil {
+		return err
+	}
+	if filter.To != nil {
+		end := filter.To.AddDate(0, 0, 1)
+		filter.To = &end
+	}
+	return nil
+}
+
+func parseDate(key, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be a date in YYYY-MM-DD format", ErrInvalidOrder, key)
+	}
+	return &t, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sellerOrderServiceImpl) GetOrder(userID, id uint) (*models.TrxToko, error) {
+	toko, err := s.myToko(userID)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.trxRepo.FindSubOrder(id, toko.ID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrOrderNotFound
+	}
+	return sub, nil
+}
+
+func (s *sellerOrderServiceImpl) myToko(userID uint) (*models.Toko, error) {
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil {
+		return nil, ErrTokoNotFound
+	}
+	return toko, nil
+}
+
+// AcceptOrder hanya bisa dilakukan untuk pesanan yang sudah dibayar.
+func (s *sellerOrderServiceImpl) AcceptOrder(userID, id uint) (*models.TrxToko, error) {
+	sub, err := s.GetOrder(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Status = models.TrxStatusProcessing
+	if err := s.update(sub, models.TrxStatusPaid); err != nil {
+		return nil, err
+	}
+	s.notify(sub, "Pesanan diproses", fmt.Sprintf("Pesanan %s sedang diproses oleh penjual.", sub.KodeInvoice))
+	return sub, nil
+}
+
+// ShipOrder hanya bisa dilakukan untuk pesanan yang sudah diterima seller.
+// Shipment dibuat dengan kurir pilihan pembeli dan event shipped.
+func (s *sellerOrderServiceImpl) ShipOrder(userID, id uint, noResi string) (*models.TrxToko, error) {
+	noResi = strings.TrimSpace(noResi)
+	if noResi == "" {
+		return nil, fmt.Errorf("%w: no_resi is required", ErrInvalidOrder)
+	}
+	sub, err := s.GetOrder(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	sub.Status = models.TrxStatusShipped
+	sub.NoResi = noResi
+	sub.ShippedAt = &now
+	sub.Shipment = &models.Shipment{
+		IDTrxToko: sub.ID,
+		IDTrx:     sub.IDTrx,
+		Kurir:     sub.Trx.Kurir,
+		NoResi:    noResi,
+		Status:    models.ShipmentShipped,
+		Events: []models.ShipmentEvent{{
+			Status:     models.ShipmentShipped,
+			Keterangan: "pesanan diserahkan ke kurir",
+			Sumber:     models.ShipmentSourceSeller,
+			Waktu:      now,
+		}},
+	}
+	changed, err := s.trxRepo.ShipSubOrder(sub, sub.Shipment, models.TrxStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: order must be %s", ErrInvalidStatus, models.TrxStatusProcessing)
+	}
+	s.notify(sub, "Pesanan dikirim", fmt.Sprintf("Pesanan %s sudah dikirim dengan nomor resi %s.", sub.KodeInvoice, noResi))
+	return sub, nil
+}
+
+// RejectOrder bisa dilakukan sebelum pesanan dikirim. Stok item pesanan
+// dikembalikan dan total sub-order dicatat sebagai refund tertunda lalu
+// langsung dikirim ke provider; refund yang gagal dicoba ulang worker.
+func (s *sellerOrderServiceImpl) RejectOrder(userID, id uint, alasan string) (*models.TrxToko, error) {
+	sub, err := s.GetOrder(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	from := sub.Status
+	sub.Status = models.TrxStatusRejected
+	sub.AlasanTolak = strings.TrimSpace(alasan)
+	changed, err := s.trxRepo.RejectSubOrder(sub, userID, models.TrxStatusPaid, models.TrxStatusProcessing)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: order is %s", ErrInvalidStatus, from)
+	}
+	if err := s.paymentService.SettleRefund(sub.IDTrx); err != nil {
+		log.Printf("Refund pesanan %s gagal, akan dicoba ulang: %v", sub.KodeInvoice, err)
+	}
+
+	pesan := fmt.Sprintf("Pesanan %s ditolak penjual.", sub.KodeInvoice)
+	if sub.AlasanTolak != "" {
+		pesan = fmt.Sprintf("Pesanan %s ditolak penjual: %s", sub.KodeInvoice, sub.AlasanTolak)
+	}
+	s.notify(sub, "Pesanan ditolak", pesan)
+	return sub, nil
+}
+
+// CollectCOD dipakai seller untuk mengonfirmasi uang pesanan COD sudah
+// diterima. Hanya bisa dilakukan sekali, setelah pesanan dikirim.
+func (s *sellerOrderServiceImpl) CollectCOD(userID, id uint) (*models.TrxToko, error) {
+	sub, err := s.GetOrder(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Trx.MethodBayar != models.PaymentMethodCOD {
+		return nil, fmt.Errorf("%w: order is not cod", ErrInvalidOrder)
+	}
+	now := time.Now()
+	sub.CODCollectedAt = &now
+	changed, err := s.trxRepo.CollectCOD(sub)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, fmt.Errorf("%w: cod can be collected once after the order is shipped", ErrInvalidStatus)
+	}
+	s.notify(sub, "Pembayaran COD diterima", fmt.Sprintf("Pembayaran COD pesanan %s sudah diterima penjual.", sub.KodeInvoice))
+	return sub, nil
+}
+
+// update menyimpan sub hanya bila statusnya di database masih fromStatus.
+func (s *sellerOrderServiceImpl) update(sub *models.TrxToko, fromStatus string) error {
+	changed, err := s.trxRepo.UpdateSubOrder(sub, fromStatus)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return fmt.Errorf("%w: order must be %s", ErrInvalidStatus, fromStatus)
+	}
+	return nil
+}
+
+func (s *sellerOrderServiceImpl) notify(sub *models.TrxToko, judul, pesan string) {
+	err := s.notificationService.Notify(&models.Notification{
+		IDUser: sub.Trx.IDUser,
+		Tipe:   models.NotificationOrderUpdate,
+		Judul:  judul,
+		Pesan:  pesan,
+	})
+	if err != nil {
+		log.Printf("Gagal menyimpan notifikasi pesanan %s: %v", sub.KodeInvoice, err)
+	}
+}
@@ -0,0 +1,176 @@
+package shipment_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"test-rakamin/internal/models"
+	shipment_repository "test-rakamin/internal/repository/shipment"
+	toko_repository "test-rakamin/internal/repository/toko"
+	trx_repository "test-rakamin/internal/repository/trx"
+	notification_service "test-rakamin/internal/service/notification"
+	"test-rakamin/pkg/webhook"
+)
+
+var (
+	ErrOrderNotFound    = errors.New("order not found")
+	ErrShipmentNotFound = errors.New("shipment not found")
+	ErrInvalidEvent     = errors.New("invalid shipment event")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrDelivered        = errors.New("shipment has already been delivered")
+)
+
+// eventStatuses adalah status event yang bisa ditambahkan setelah
+// pengiriman dibuat.
+var eventStatuses = []string{
+	models.ShipmentInTransit,
+	models.ShipmentOutForDelivery,
+	models.ShipmentFailedAttempt,
+	models.ShipmentDelivered,
+}
+
+type ShipmentService interface {
+	AddEvent(userID, trxTokoID uint, payload *models.ShipmentEventPayload) (*models.Shipment, error)
+	HandleCourierWebhook(kurir string, body []byte, signature string) error
+}
+
+type shipmentServiceImpl struct {
+	shipmentRepo        shipment_repository.ShipmentRepository
+	trxRepo             trx_repository.TrxRepository
+	tokoRepo            toko_repository.TokoRepository
+	notificationService notification_service.NotificationService
+}
+
+func NewShipmentService(repo shipment_repository.ShipmentRepository, trxRepo trx_repository.TrxRepository, tokoRepo toko_repository.TokoRepository, notificationService notification_service.NotificationService) ShipmentService {
+	return &shipmentServiceImpl{shipmentRepo: repo, trxRepo: trxRepo, tokoRepo: tokoRepo, notificationService: notificationService}
+}
+
+// AddEvent menambahkan event ke timeline pengiriman sub-order toko milik
+// userID.
+func (s *shipmentServiceImpl) AddEvent(userID, trxTokoID uint, payload *models.ShipmentEventPayload) (*models.Shipment, error) {
+	event, err := newEvent(payload, models.ShipmentSourceSeller)
+	if err != nil {
+		return nil, err
+	}
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil {
+		return nil, ErrOrderNotFound
+	}
+	sub, err := s.trxRepo.FindSubOrder(trxTokoID, toko.ID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrOrderNotFound
+	}
+	if sub.Shipment == nil {
+		return nil, fmt.Errorf("%w: order %s has not been shipped", ErrShipmentNotFound, sub.KodeInvoice)
+	}
+
+	if err := s.addEvent(sub.Shipment, event); err != nil {
+		return nil, err
+	}
+	if event.Status == models.ShipmentDelivered {
+		s.notifyDelivered(sub)
+	}
+	return sub.Shipment, nil
+}
+
+// HandleCourierWebhook memproses update status dari kurir. Body harus
+// ditandatangani seperti webhook.Sign dengan SHIPPING_WEBHOOK_SECRET; bila
+// secret kosong semua webhook ditolak. Event dengan event_id yang sudah
+// pernah diterima diabaikan.
+func (s *shipmentServiceImpl) HandleCourierWebhook(kurir string, body []byte, signature string) error {
+	if !webhook.Verify(os.Getenv("SHIPPING_WEBHOOK_SECRET"), body, signature) {
+		return ErrInvalidSignature
+	}
+	var payload models.ShipmentEventPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	event, err := newEvent(&payload, models.ShipmentSourceCourier)
+	if err != nil {
+		return err
+	}
+	if eventID := strings.TrimSpace(payload.EventID); eventID != "" {
+		event.EventID = &eventID
+	}
+
+	kurir = strings.ToLower(kurir)
+	shipment, err := s.shipmentRepo.FindByResi(kurir, strings.TrimSpace(payload.NoResi))
+	if err != nil {
+		return err
+	}
+	if shipment == nil {
+		return fmt.Errorf("%w: no shipment for kurir %s and no_resi %q", ErrShipmentNotFound, kurir, payload.NoResi)
+	}
+
+	err = s.addEvent(shipment, event)
+	if errors.Is(err, shipment_repository.ErrDuplicateEvent) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if event.Status == models.ShipmentDelivered {
+		s.notifyDelivered(shipment.TrxToko)
+	}
+	return nil
+}
+
+func newEvent(payload *models.ShipmentEventPayload, sumber string) (*models.ShipmentEvent, error) {
+	event := &models.ShipmentEvent{
+		Status:     strings.ToLower(strings.TrimSpace(payload.Status)),
+		Keterangan: strings.TrimSpace(payload.Keterangan),
+		Lokasi:     strings.TrimSpace(payload.Lokasi),
+		Sumber:     sumber,
+		Waktu:      time.Now(),
+	}
+	valid := false
+	for _, status := range eventStatuses {
+		valid = valid || event.Status == status
+	}
+	if !valid {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalidEvent, strings.Join(eventStatuses, ", "))
+	}
+	if payload.Waktu != nil {
+		if payload.Waktu.After(time.Now().Add(time.Minute)) {
+			return nil, fmt.Errorf("%w: waktu must not be in the future", ErrInvalidEvent)
+		}
+		event.Waktu = *payload.Waktu
+	}
+	return event, nil
+}
+
+// addEvent menambahkan event ke shipment dan timeline yang dikembalikan.
+func (s *shipmentServiceImpl) addEvent(shipment *models.Shipment, event *models.ShipmentEvent) error {
+	err := s.shipmentRepo.AddEvent(shipment, event)
+	if errors.Is(err, shipment_repository.ErrDelivered) {
+		return ErrDelivered
+	}
+	if err != nil {
+		return err
+	}
+	shipment.Events = append(shipment.Events, *event)
+	return nil
+}
+
+func (s *shipmentServiceImpl) notifyDelivered(sub *models.TrxToko) {
+	err := s.notificationService.Notify(&models.Notification{
+		IDUser: sub.Trx.IDUser,
+		Tipe:   models.NotificationOrderUpdate,
+		Judul:  "Pesanan sampai",
+		Pesan:  fmt.Sprintf("Pesanan %s sudah sampai di alamat tujuan.", sub.KodeInvoice),
+	})
+	if err != nil {
+		log.Printf("Gagal menyimpan notifikasi pesanan %s: %v", sub.KodeInvoice, err)
+	}
+}
@@ -0,0 +1,174 @@
+package shipping_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"test-rakamin/internal/models"
+	alamat_repository "test-rakamin/internal/repository/alamat"
+	product_repository "test-rakamin/internal/repository/product"
+	user_repository "test-rakamin/internal/repository/user"
+	"test-rakamin/pkg/shipping"
+)
+
+var (
+	ErrInvalidShipping = errors.New("invalid shipping request")
+	ErrNotServed       = errors.New("courier does not serve this route")
+)
+
+type ShippingService interface {
+	GetQuotes(userID uint, payload *models.ShippingQuotePayload) ([]models.ShippingOption, error)
+	Quote(userID, alamatID uint, kurir string, items []models.DetailTrxPayload) (*models.ShippingOption, error)
+}
+
+type shippingServiceImpl struct {
+	couriers    []shipping.Courier
+	alamatRepo  alamat_repository.AlamatRepository
+	userRepo    user_repository.UserRepository
+	productRepo product_repository.ProductRepository
+}
+
+func NewShippingService(couriers []shipping.Courier, alamatRepo alamat_repository.AlamatRepository, userRepo user_repository.UserRepository, productRepo product_repository.ProductRepository) ShippingService {
+	return &shippingServiceImpl{couriers: couriers, alamatRepo: alamatRepo, userRepo: userRepo, productRepo: productRepo}
+}
+
+// GetQuotes menghitung ongkir setiap kurir untuk item payload. Kurir yang
+// tidak melayani salah satu rute tidak ikut ditampilkan.
+func (s *shippingServiceImpl) GetQuotes(userID uint, payload *models.ShippingQuotePayload) ([]models.ShippingOption, error) {
+	parcels, err := s.parcels(userID, payload.AlamatKirim, payload.DetailTrx)
+	if err != nil {
+		return nil, err
+	}
+	options := []models.ShippingOption{}
+	for _, courier := range s.couriers {
+		option, err := quote(courier, parcels)
+		if errors.Is(err, ErrNotServed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, *option)
+	}
+	return options, nil
+}
+
+// Quote menghitung ongkir kurir untuk item yang akan di-checkout, satu
+// paket per toko.
+func (s *shippingServiceImpl) Quote(userID, alamatID uint, kurir string, items []models.DetailTrxPayload) (*models.ShippingOption, error) {
+	kurir = strings.ToLower(strings.TrimSpace(kurir))
+	var courier shipping.Courier
+	for _, c := range s.couriers {
+		if c.Code() == kurir {
+			courier = c
+			break
+		}
+	}
+	if courier == nil {
+		return nil, fmt.Errorf("%w: kurir %q is not available", ErrInvalidShipping, kurir)
+	}
+
+	parcels, err := s.parcels(userID, alamatID, items)
+	if err != nil {
+		return nil, err
+	}
+	return quote(courier, parcels)
+}
+
+// tokoParcel adalah paket dari satu toko.
+type tokoParcel struct {
+	idToko uint
+	parcel shipping.Parcel
+}
+
+// parcels mengelompokkan item per toko sesuai urutan item, sama seperti
+// sub-order transaksi.
+func (s *shippingServiceImpl) parcels(userID, alamatID uint, items []models.DetailTrxPayload) ([]tokoParcel, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: detail_trx is required", ErrInvalidShipping)
+	}
+	tujuan, err := s.destination(userID, alamatID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.productRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	var result []tokoParcel
+	index := map[uint]int{}
+	for _, item := range items {
+		product, ok := byID[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: product with ID %d not found", ErrInvalidShipping, item.ProductID)
+		}
+		if item.Kuantitas <= 0 {
+			return nil, fmt.Errorf("%w: kuantitas for product with ID %d must be positive", ErrInvalidShipping, item.ProductID)
+		}
+		i, ok := index[product.IDToko]
+		if !ok {
+			i = len(result)
+			index[product.IDToko] = i
+			result = append(result, tokoParcel{
+				idToko: product.IDToko,
+				parcel: shipping.Parcel{AsalKota: product.Toko.IDKota, TujuanKota: tujuan},
+			})
+		}
+		parcel := &result[i].parcel
+		parcel.BeratGram += product.BeratGram * item.Kuantitas
+		parcel.VolumeCm3 += product.PanjangCm * product.LebarCm * product.TinggiCm * item.Kuantitas
+	}
+	return result, nil
+}
+
+// destination memakai kota alamat, atau kota user bila alamat belum
+// memiliki IDKota.
+func (s *shippingServiceImpl) destination(userID, alamatID uint) (uint, error) {
+	alamat, err := s.alamatRepo.FindByIDAndUserID(alamatID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if alamat == nil {
+		return 0, fmt.Errorf("%w: alamat %d not found", ErrInvalidShipping, alamatID)
+	}
+	if alamat.IDKota != 0 {
+		return alamat.IDKota, nil
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return 0, err
+	}
+	return user.IDKota, nil
+}
+
+func quote(courier shipping.Courier, parcels []tokoParcel) (*models.ShippingOption, error) {
+	option := &models.ShippingOption{Kurir: courier.Code()}
+	for _, p := range parcels {
+		q, err := courier.Quote(p.parcel)
+		if errors.Is(err, shipping.ErrNotServed) {
+			return nil, fmt.Errorf("%w: kurir %s cannot ship from toko %d", ErrNotServed, courier.Code(), p.idToko)
+		}
+		if err != nil {
+			return nil, err
+		}
+		option.Ongkir += q.Ongkir
+		option.PerToko = append(option.PerToko, models.ShippingQuote{
+			IDToko:   p.idToko,
+			BeratKg:  p.parcel.Kg(),
+			Ongkir:   q.Ongkir,
+			Estimasi: q.Estimasi,
+		})
+	}
+	return option, nil
+}
@@ -0,0 +1,177 @@
+package stock_alert_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	stock_reservation_repository "test-rakamin/internal/repository/stock_reservation"
+	toko_repository "test-rakamin/internal/repository/toko"
+	notification_service "test-rakamin/internal/service/notification"
+	"test-rakamin/pkg/webhook"
+)
+
+type StockAlertService interface {
+	Evaluate(productIDs ...uint)
+	GetLowStockProducts(userID uint) ([]models.Product, error)
+}
+
+// StockAlertPayload adalah isi data webhook low_stock dan out_of_stock.
+type StockAlertPayload struct {
+	ProductID         uint   `json:"product_id"`
+	TokoID            uint   `json:"toko_id"`
+	NamaProduct       string `json:"nama_product"`
+	Stok              int    `json:"stok"`
+	StokTersedia      int    `json:"stok_tersedia"`
+	LowStockThreshold int    `json:"low_stock_threshold"`
+}
+
+type stockAlertServiceImpl struct {
+	productRepo         product_repository.ProductRepository
+	tokoRepo            toko_repository.TokoRepository
+	reservationRepo     stock_reservation_repository.StockReservationRepository
+	notificationService notification_service.NotificationService
+}
+
+func NewStockAlertService(productRepo product_repository.ProductRepository, tokoRepo toko_repository.TokoRepository, reservationRepo stock_reservation_repository.StockReservationRepository, notificationService notification_service.NotificationService) StockAlertService {
+	return &stockAlertServiceImpl{productRepo: productRepo, tokoRepo: tokoRepo, reservationRepo: reservationRepo, notificationService: notificationService}
+}
+
+// alertLevel menentukan peringatan yang berlaku untuk stok tersedia.
+func alertLevel(stokTersedia, threshold int) string {
+	switch {
+	case stokTersedia <= 0:
+		return models.StockAlertOut
+	case stokTersedia <= threshold:
+		return models.StockAlertLow
+	default:
+		return ""
+	}
+}
+
+func alertRank(level string) int {
+	switch level {
+	case models.StockAlertOut:
+		return 2
+	case models.StockAlertLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Evaluate dipanggil setelah stok tersedia berubah (checkout, edit stok,
+// reservasi dilepas). Seller hanya diberi tahu saat stok turun melewati
+// batas; AlertStok disimpan supaya peringatan yang sama tidak terkirim
+// berulang. Kegagalan hanya dicatat ke log agar tidak menggagalkan proses
+// pemanggilnya.
+func (s *stockAlertServiceImpl) Evaluate(productIDs ...uint) {
+	reserved, err := s.reservationRepo.ReservedTotals(productIDs)
+	if err != nil {
+		log.Printf("Gagal menghitung reservasi untuk stock alert: %v", err)
+		return
+	}
+
+	for _, id := range productIDs {
+		product, err := s.productRepo.FindByID(id)
+		if err != nil || product == nil {
+			log.Printf("Gagal memuat produk %d untuk stock alert: %v", id, err)
+			continue
+		}
+
+		stokTersedia := product.Stok - reserved.Product[id]
+		level := alertLevel(stokTersedia, product.LowStockThreshold)
+		if level == product.AlertStok {
+			continue
+		}
+		changed, err := s.productRepo.UpdateAlertStok(id, product.AlertStok, level)
+		if err != nil {
+			log.Printf("Gagal menyimpan stock alert produk %d: %v", id, err)
+			continue
+		}
+		if !changed || alertRank(level) <= alertRank(product.AlertStok) {
+			continue
+		}
+
+		s.send(product, level, stokTersedia)
+	}
+}
+
+func (s *stockAlertServiceImpl) send(product *models.Product, level string, stokTersedia int) {
+	judul := "Stok produk menipis"
+	pesan := fmt.Sprintf("Stok %s tinggal %d (batas %d).", product.NamaProduct, stokTersedia, product.LowStockThreshold)
+	if level == models.StockAlertOut {
+		judul = "Stok produk habis"
+		pesan = fmt.Sprintf("Stok %s sudah habis.", product.NamaProduct)
+	}
+
+	productID := product.ID
+	err := s.notificationService.Notify(&models.Notification{
+		IDUser:    product.Toko.IDUser,
+		Tipe:      level,
+		Judul:     judul,
+		Pesan:     pesan,
+		ProductID: &productID,
+	})
+	if err != nil {
+		log.Printf("Gagal menyimpan notifikasi stok produk %d: %v", product.ID, err)
+	}
+
+	if product.Toko.WebhookURL == "" {
+		return
+	}
+	payload := StockAlertPayload{
+		ProductID:         product.ID,
+		TokoID:            product.IDToko,
+		NamaProduct:       product.NamaProduct,
+		Stok:              product.Stok,
+		StokTersedia:      stokTersedia,
+		LowStockThreshold: product.LowStockThreshold,
+	}
+	go func(url, secret string) {
+		if err := webhook.Send(url, secret, level, payload); err != nil {
+			log.Printf("Gagal mengirim webhook %s produk %d: %v", level, payload.ProductID, err)
+		}
+	}(product.Toko.WebhookURL, product.Toko.WebhookSecret)
+}
+
+// GetLowStockProducts mengembalikan produk toko milik userID yang stok
+// tersedianya sudah di bawah batas atau habis.
+func (s *stockAlertServiceImpl) GetLowStockProducts(userID uint) ([]models.Product, error) {
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil {
+		return nil, errors.New("toko not found")
+	}
+
+	products, err := s.productRepo.FindByTokoID(toko.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	reserved, err := s.reservationRepo.ReservedTotals(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	lowStock := make([]models.Product, 0)
+	for _, product := range products {
+		product.StokTersedia = max(product.Stok-reserved.Product[product.ID], 0)
+		if alertLevel(product.StokTersedia, product.LowStockThreshold) == "" {
+			continue
+		}
+		for i := range product.ProductVariant {
+			variant := &product.ProductVariant[i]
+			variant.StokTersedia = max(variant.Stok-reserved.Variant[variant.ID], 0)
+		}
+		lowStock = append(lowStock, product)
+	}
+	return lowStock, nil
+}
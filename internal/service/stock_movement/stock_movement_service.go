@@ -0,0 +1,59 @@
+package stock_movement_service
+
+import (
+	"errors"
+
+	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	stock_movement_repository "test-rakamin/internal/repository/stock_movement"
+	toko_repository "test-rakamin/internal/repository/toko"
+)
+
+var ErrForbidden = errors.New("product does not belong to your toko")
+
+type StockMovementService interface {
+	GetStockMovements(userID, productID uint) ([]models.StockMovement, error)
+	ReconcileStock(userID, productID uint) (*stock_movement_repository.ReconcileResult, error)
+}
+
+type stockMovementServiceImpl struct {
+	stockRepo   stock_movement_repository.StockMovementRepository
+	productRepo product_repository.ProductRepository
+	tokoRepo    toko_repository.TokoRepository
+}
+
+func NewStockMovementService(repo stock_movement_repository.StockMovementRepository, productRepo product_repository.ProductRepository, tokoRepo toko_repository.TokoRepository) StockMovementService {
+	return &stockMovementServiceImpl{stockRepo: repo, productRepo: productRepo, tokoRepo: tokoRepo}
+}
+
+func (s *stockMovementServiceImpl) GetStockMovements(userID, productID uint) ([]models.StockMovement, error) {
+	if err := s.checkOwner(userID, productID); err != nil {
+		return nil, err
+	}
+	return s.stockRepo.FindByProductID(productID)
+}
+
+func (s *stockMovementServiceImpl) ReconcileStock(userID, productID uint) (*stock_movement_repository.ReconcileResult, error) {
+	if err := s.checkOwner(userID, productID); err != nil {
+		return nil, err
+	}
+	return s.stockRepo.Reconcile(productID)
+}
+
+func (s *stockMovementServiceImpl) checkOwner(userID, productID uint) error {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return errors.New("product not found")
+	}
+	toko, err := s.tokoRepo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if toko == nil || toko.ID != product.IDToko {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -1,26 +1,41 @@
 package toko_service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
 	"mime/multipart"
 	"test-rakamin/internal/models"
 	toko_repository "test-rakamin/internal/repository/toko"
-	"test-rakamin/utils"
+	upload_service "test-rakamin/internal/service/upload"
+	"test-rakamin/pkg/safehttp"
+	"test-rakamin/pkg/webhook"
 )
 
 type TokoService interface {
 	GetTokoByUserID(userID uint) (*models.Toko, error)
 	GetAllToko() ([]models.Toko, error)
 	GetTokoByID(id uint) (*models.Toko, error)
-	UpdateToko(id uint, namaToko string, photo *multipart.FileHeader) (*models.Toko, error)
+	UpdateToko(userID, id uint, namaToko, webhookURL string, idKota uint, photo *multipart.FileHeader) (*models.Toko, error)
+	UpdateCOD(userID, id uint, payload *models.TokoCODPayload) (*models.Toko, error)
+	GetWebhookSecret(userID, id uint) (*models.TokoWebhookSecret, error)
+	RotateWebhookSecret(userID, id uint) (*models.TokoWebhookSecret, error)
 }
 
+var (
+	ErrForbidden         = errors.New("toko does not belong to you")
+	ErrInvalidWebhookURL = errors.New("webhook url must be a public http or https url")
+	ErrInvalidCOD        = errors.New("maks_total must not be negative")
+)
+
 type tokoServiceImpl struct {
-	tokoRepo toko_repository.TokoRepository
+	tokoRepo      toko_repository.TokoRepository
+	uploadService upload_service.UploadService
 }
 
-func NewTokoService(repo toko_repository.TokoRepository) TokoService {
-	return &tokoServiceImpl{tokoRepo: repo}
+func NewTokoService(repo toko_repository.TokoRepository, uploadService upload_service.UploadService) TokoService {
+	return &tokoServiceImpl{tokoRepo: repo, uploadService: uploadService}
 }
 
 func (s *tokoServiceImpl) GetTokoByUserID(userID uint) (*models.Toko, error) {
@@ -31,11 +46,20 @@ func (s *tokoServiceImpl) GetTokoByUserID(userID uint) (*models.Toko, error) {
 	if toko == nil {
 		return nil, errors.New("toko not found")
 	}
+	toko.FotoTokoVariants = s.uploadService.Variants(toko.URLFotoToko)
 	return toko, nil
 }
 
 func (s *tokoServiceImpl) GetAllToko() ([]models.Toko, error) {
-	return s.tokoRepo.FindAll()
+	tokoList, err := s.tokoRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokoList {
+		tokoList[i].FotoTokoVariants = s.uploadService.Variants(tokoList[i].URLFotoToko)
+		tokoList[i].WebhookURL = ""
+	}
+	return tokoList, nil
 }
 
 func (s *tokoServiceImpl) GetTokoByID(id uint) (*models.Toko, error) {
@@ -46,10 +70,16 @@ func (s *tokoServiceImpl) GetTokoByID(id uint) (*models.Toko, error) {
 	if toko == nil {
 		return nil, errors.New("toko not found")
 	}
+	toko.FotoTokoVariants = s.uploadService.Variants(toko.URLFotoToko)
+	// Webhook URL bisa berisi token milik seller, jadi hanya ditampilkan
+	// lewat /api/toko/my.
+	toko.WebhookURL = ""
 	return toko, nil
 }
 
-func (s *tokoServiceImpl) UpdateToko(id uint, namaToko string, photo *multipart.FileHeader) (*models.Toko, error) {
+// UpdateToko mengubah toko milik userID. webhookURL kosong mematikan
+// webhook peringatan stok, idKota 0 membiarkan kota asal pengiriman.
+func (s *tokoServiceImpl) UpdateToko(userID, id uint, namaToko, webhookURL string, idKota uint, photo *multipart.FileHeader) (*models.Toko, error) {
 	existingToko, err := s.tokoRepo.FindByID(id)
 	if err != nil {
 		return nil, err
@@ -57,12 +87,32 @@ func (s *tokoServiceImpl) UpdateToko(id uint, namaToko string, photo *multipart.
 	if existingToko == nil {
 		return nil, errors.New("toko not found")
 	}
+	if existingToko.IDUser != userID {
+		return nil, ErrForbidden
+	}
+	if webhookURL != "" {
+		if err := safehttp.CheckURL(context.Background(), webhookURL); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+		}
+	}
 
 	existingToko.NamaToko = namaToko
+	existingToko.WebhookURL = webhookURL
+	if webhookURL != "" && existingToko.WebhookSecret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			return nil, err
+		}
+		existingToko.WebhookSecret = secret
+	}
+	if idKota != 0 {
+		existingToko.IDKota = idKota
+	}
 
+	oldPhotoURL := existingToko.URLFotoToko
 	if photo != nil {
 
-		photoURL, err := utils.SaveUploadedFile(photo)
+		photoURL, err := s.uploadService.SaveUploadedFile(photo)
 		if err != nil {
 			return nil, err
 		}
@@ -71,8 +121,94 @@ func (s *tokoServiceImpl) UpdateToko(id uint, namaToko string, photo *multipart.
 
 	err = s.tokoRepo.Update(existingToko)
 	if err != nil {
+		if photo != nil {
+			if err := s.uploadService.Release(existingToko.URLFotoToko); err != nil {
+				log.Printf("failed to release toko photo %s: %v", existingToko.URLFotoToko, err)
+			}
+		}
 		return nil, err
 	}
+	if photo != nil && oldPhotoURL != "" {
+		if err := s.uploadService.Release(oldPhotoURL); err != nil {
+			log.Printf("failed to release toko photo %s: %v", oldPhotoURL, err)
+		}
+	}
 
+	existingToko.FotoTokoVariants = s.uploadService.Variants(existingToko.URLFotoToko)
 	return existingToko, nil
 }
+
+// UpdateCOD mengatur apakah toko milik userID menerima COD dan batas total
+// sub-order COD-nya.
+func (s *tokoServiceImpl) UpdateCOD(userID, id uint, payload *models.TokoCODPayload) (*models.Toko, error) {
+	if payload.MaksTotal < 0 {
+		return nil, ErrInvalidCOD
+	}
+	toko, err := s.tokoRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil {
+		return nil, errors.New("toko not found")
+	}
+	if toko.IDUser != userID {
+		return nil, ErrForbidden
+	}
+
+	toko.CODAktif = payload.Aktif
+	toko.CODMaksTotal = payload.MaksTotal
+	if err := s.tokoRepo.Update(toko); err != nil {
+		return nil, err
+	}
+	toko.FotoTokoVariants = s.uploadService.Variants(toko.URLFotoToko)
+	return toko, nil
+}
+
+// GetWebhookSecret mengembalikan secret penandatangan webhook toko milik
+// userID. Toko lama yang belum punya secret langsung dibuatkan.
+func (s *tokoServiceImpl) GetWebhookSecret(userID, id uint) (*models.TokoWebhookSecret, error) {
+	toko, err := s.ownedToko(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if toko.WebhookSecret == "" {
+		return s.rotateWebhookSecret(toko)
+	}
+	return &models.TokoWebhookSecret{Secret: toko.WebhookSecret}, nil
+}
+
+// RotateWebhookSecret mengganti secret webhook toko milik userID. Secret
+// lama langsung tidak berlaku untuk webhook berikutnya.
+func (s *tokoServiceImpl) RotateWebhookSecret(userID, id uint) (*models.TokoWebhookSecret, error) {
+	toko, err := s.ownedToko(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.rotateWebhookSecret(toko)
+}
+
+func (s *tokoServiceImpl) rotateWebhookSecret(toko *models.Toko) (*models.TokoWebhookSecret, error) {
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	toko.WebhookSecret = secret
+	if err := s.tokoRepo.Update(toko); err != nil {
+		return nil, err
+	}
+	return &models.TokoWebhookSecret{Secret: secret}, nil
+}
+
+func (s *tokoServiceImpl) ownedToko(userID, id uint) (*models.Toko, error) {
+	toko, err := s.tokoRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if toko == nil {
+		return nil, errors.New("toko not found")
+	}
+	if toko.IDUser != userID {
+		return nil, ErrForbidden
+	}
+	return toko, nil
+}
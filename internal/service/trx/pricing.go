@@ -0,0 +1,46 @@
+package trx_service
+
+import (
+	"test-rakamin/internal/models"
+	discount_service "test-rakamin/internal/service/discount"
+)
+
+// unitPrice menentukan harga satuan termurah yang berhak didapat pembeli
+// beserta tier yang dipakai: harga konsumen, harga reseller (hanya untuk
+// reseller yang sudah disetujui), harga grosir berdasarkan kuantitas, atau
+// harga konsumen setelah diskon terbaik dari discounts. Diskon yang
+// dikembalikan tidak nil hanya bila harga diskon yang dipakai.
+func unitPrice(product *models.Product, variant *models.ProductVariant, isReseller bool, kuantitas int, discounts []models.Discount) (int, string, *models.Discount) {
+	harga, tier := product.HargaKonsumen, models.TierHargaKonsumen
+	if variant != nil && variant.HargaKonsumen != nil {
+		harga = *variant.HargaKonsumen
+	}
+	discount, hargaDiskon := discount_service.Best(discounts, harga)
+
+	if isReseller {
+		hargaReseller := product.HargaReseller
+		if variant != nil && variant.HargaReseller != nil {
+			hargaReseller = *variant.HargaReseller
+		}
+		if hargaReseller > 0 && hargaReseller < harga {
+			harga, tier = hargaReseller, models.TierHargaReseller
+		}
+	}
+
+	// Tier grosir dengan MinKuantitas tertinggi yang tercapai yang berlaku.
+	var grosir *models.ProductPriceTier
+	for i := range product.PriceTier {
+		t := &product.PriceTier[i]
+		if kuantitas >= t.MinKuantitas && (grosir == nil || t.MinKuantitas > grosir.MinKuantitas) {
+			grosir = t
+		}
+	}
+	if grosir != nil && grosir.Harga < harga {
+		harga, tier = grosir.Harga, models.TierHargaGrosir
+	}
+
+	if discount != nil && hargaDiskon < harga {
+		return hargaDiskon, models.TierHargaDiskon, discount
+	}
+	return harga, tier, nil
+}
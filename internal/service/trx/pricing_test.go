@@ -0,0 +1,62 @@
+package trx_service
+
+import (
+	"testing"
+
+	"test-rakamin/internal/models"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestUnitPrice(t *testing.T) {
+	product := &models.Product{
+		HargaKonsumen: 10000,
+		HargaReseller: 8000,
+		PriceTier: []models.ProductPriceTier{
+			{MinKuantitas: 10, Harga: 7500},
+			{MinKuantitas: 50, Harga: 7000},
+		},
+	}
+	variant := &models.ProductVariant{HargaKonsumen: intPtr(12000), HargaReseller: intPtr(9000)}
+	diskon := models.Discount{Tipe: models.DiscountPercent, Nilai: 40}
+	kecil := models.Discount{Tipe: models.DiscountFixed, Nilai: 500}
+
+	tests := []struct {
+		name         string
+		product      *models.Product
+		variant      *models.ProductVariant
+		isReseller   bool
+		kuantitas    int
+		discounts    []models.Discount
+		wantHarga    int
+		wantTier     string
+		wantDiscount bool
+	}{
+		{name: "konsumen", product: product, kuantitas: 1, wantHarga: 10000, wantTier: models.TierHargaKonsumen},
+		{name: "reseller", product: product, isReseller: true, kuantitas: 1, wantHarga: 8000, wantTier: models.TierHargaReseller},
+		{name: "grosir tercapai", product: product, kuantitas: 10, wantHarga: 7500, wantTier: models.TierHargaGrosir},
+		{name: "grosir tertinggi yang tercapai", product: product, kuantitas: 60, wantHarga: 7000, wantTier: models.TierHargaGrosir},
+		{name: "grosir lebih murah dari reseller", product: product, isReseller: true, kuantitas: 10, wantHarga: 7500, wantTier: models.TierHargaGrosir},
+		{name: "harga varian", product: product, variant: variant, kuantitas: 1, wantHarga: 12000, wantTier: models.TierHargaKonsumen},
+		{name: "reseller varian", product: product, variant: variant, isReseller: true, kuantitas: 1, wantHarga: 9000, wantTier: models.TierHargaReseller},
+		{name: "varian tanpa harga sendiri", product: product, variant: &models.ProductVariant{}, kuantitas: 1, wantHarga: 10000, wantTier: models.TierHargaKonsumen},
+		{name: "reseller tidak dipakai bila lebih mahal", product: &models.Product{HargaKonsumen: 5000, HargaReseller: 6000}, isReseller: true, kuantitas: 1, wantHarga: 5000, wantTier: models.TierHargaKonsumen},
+		{name: "reseller kosong diabaikan", product: &models.Product{HargaKonsumen: 5000}, isReseller: true, kuantitas: 1, wantHarga: 5000, wantTier: models.TierHargaKonsumen},
+		{name: "diskon termurah", product: product, kuantitas: 10, discounts: []models.Discount{kecil, diskon}, wantHarga: 6000, wantTier: models.TierHargaDiskon, wantDiscount: true},
+		{name: "diskon kalah dari grosir", product: product, kuantitas: 10, discounts: []models.Discount{kecil}, wantHarga: 7500, wantTier: models.TierHargaGrosir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			harga, tier, discount := unitPrice(tt.product, tt.variant, tt.isReseller, tt.kuantitas, tt.discounts)
+			if harga != tt.wantHarga || tier != tt.wantTier {
+				t.Errorf("got %d %s, want %d %s", harga, tier, tt.wantHarga, tt.wantTier)
+			}
+			if (discount != nil) != tt.wantDiscount {
+				t.Errorf("discount = %v, wantDiscount %v", discount, tt.wantDiscount)
+			}
+		})
+	}
+}
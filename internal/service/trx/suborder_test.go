@@ -0,0 +1,56 @@
+package trx_service
+
+import (
+	"testing"
+
+	"test-rakamin/internal/models"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestSplitByToko(t *testing.T) {
+	details := []models.DetailTrx{
+		{IDToko: 1, HargaTotal: 10000},
+		{IDToko: 2, HargaTotal: 10000},
+		{IDToko: 1, HargaTotal: 5000},
+		{IDToko: 3, HargaTotal: 5000},
+	}
+
+	tests := []struct {
+		name         string
+		voucher      *models.Voucher
+		potongan     int
+		wantSubtotal []int
+		wantPotongan []int
+	}{
+		{name: "tanpa voucher", wantSubtotal: []int{15000, 10000, 5000}, wantPotongan: []int{0, 0, 0}},
+		{name: "voucher toko", voucher: &models.Voucher{IDToko: uintPtr(2)}, potongan: 3000, wantSubtotal: []int{15000, 10000, 5000}, wantPotongan: []int{0, 3000, 0}},
+		{name: "voucher admin proporsional", voucher: &models.Voucher{}, potongan: 6000, wantSubtotal: []int{15000, 10000, 5000}, wantPotongan: []int{3000, 2000, 1000}},
+		{name: "sisa pembulatan di toko terakhir", voucher: &models.Voucher{}, potongan: 1000, wantSubtotal: []int{15000, 10000, 5000}, wantPotongan: []int{500, 333, 167}},
+		{name: "voucher toko yang tidak ada di keranjang", voucher: &models.Voucher{IDToko: uintPtr(9)}, wantSubtotal: []int{15000, 10000, 5000}, wantPotongan: []int{0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subOrders := splitByToko(details, tt.voucher, tt.potongan)
+			if len(subOrders) != len(tt.wantSubtotal) {
+				t.Fatalf("got %d sub-order, want %d", len(subOrders), len(tt.wantSubtotal))
+			}
+			var total int
+			for i, sub := range subOrders {
+				if sub.Subtotal != tt.wantSubtotal[i] || sub.PotonganVoucher != tt.wantPotongan[i] {
+					t.Errorf("sub-order %d (toko %d) = %d - %d, want %d - %d", i, sub.IDToko, sub.Subtotal, sub.PotonganVoucher, tt.wantSubtotal[i], tt.wantPotongan[i])
+				}
+				if sub.HargaTotal != sub.Subtotal-sub.PotonganVoucher {
+					t.Errorf("sub-order %d HargaTotal = %d", i, sub.HargaTotal)
+				}
+				total += sub.PotonganVoucher
+			}
+			if total != tt.potongan {
+				t.Errorf("total potongan = %d, want %d", total, tt.potongan)
+			}
+		})
+	}
+}
@@ -1,29 +1,48 @@
 package trx_service
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
-	"math/rand"
+	"log"
+	"strings"
 	"time"
 
 	"test-rakamin/internal/models"
 	product_repository "test-rakamin/internal/repository/product"
 	trx_repository "test-rakamin/internal/repository/trx"
+	user_repository "test-rakamin/internal/repository/user"
+	discount_service "test-rakamin/internal/service/discount"
+	payment_service "test-rakamin/internal/service/payment"
+	shipping_service "test-rakamin/internal/service/shipping"
+	stock_alert_service "test-rakamin/internal/service/stock_alert"
+	voucher_service "test-rakamin/internal/service/voucher"
 )
 
 type TrxService interface {
 	GetAllTrxByUserID(userID uint) ([]models.Trx, error)
 	GetTrxByID(id uint, userID uint) (*models.Trx, error)
+	PreviewTrx(userID uint, payload *models.TrxPayload) (*models.Trx, error)
 	CreateTrx(userID uint, payload *models.TrxPayload) (*models.Trx, error)
+	ReleaseExpiredReservations() error
 }
 
 type trxServiceImpl struct {
-	trxRepo     trx_repository.TrxRepository
-	productRepo product_repository.ProductRepository
+	trxRepo        trx_repository.TrxRepository
+	productRepo    product_repository.ProductRepository
+	userRepo       user_repository.UserRepository
+	stockAlert     stock_alert_service.StockAlertService
+	discounts      discount_service.DiscountService
+	vouchers       voucher_service.VoucherService
+	shipping       shipping_service.ShippingService
+	payments       payment_service.PaymentService
+	reservationTTL time.Duration
 }
 
-func NewTrxService(repo trx_repository.TrxRepository, productRepo product_repository.ProductRepository) TrxService {
-	return &trxServiceImpl{trxRepo: repo, productRepo: productRepo}
+// NewTrxService membuat TrxService. reservationTTL adalah lama stok
+// ditahan untuk transaksi yang belum dibayar.
+func NewTrxService(repo trx_repository.TrxRepository, productRepo product_repository.ProductRepository, userRepo user_repository.UserRepository, stockAlert stock_alert_service.StockAlertService, discounts discount_service.DiscountService, vouchers voucher_service.VoucherService, shipping shipping_service.ShippingService, payments payment_service.PaymentService, reservationTTL time.Duration) TrxService {
+	return &trxServiceImpl{trxRepo: repo, productRepo: productRepo, userRepo: userRepo, stockAlert: stockAlert, discounts: discounts, vouchers: vouchers, shipping: shipping, payments: payments, reservationTTL: reservationTTL}
 }
 
 func (s *trxServiceImpl) GetAllTrxByUserID(userID uint) ([]models.Trx, error) {
@@ -41,47 +60,262 @@ func (s *trxServiceImpl) GetTrxByID(id uint, userID uint) (*models.Trx, error) {
 	return trx, nil
 }
 
+// PreviewTrx menghitung harga, diskon, potongan voucher dan ongkir seperti
+// CreateTrx tanpa menyimpan transaksi maupun mereservasi stok.
+func (s *trxServiceImpl) PreviewTrx(userID uint, payload *models.TrxPayload) (*models.Trx, error) {
+	return s.priceTrx(userID, payload)
+}
+
 func (s *trxServiceImpl) CreateTrx(userID uint, payload *models.TrxPayload) (*models.Trx, error) {
+	newTrx, err := s.priceTrx(userID, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	newTrx.KodeInvoice, err = newKodeInvoice(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.reservationTTL)
+	newTrx.Status = models.TrxStatusPendingPayment
+	newTrx.ExpiresAt = &expiresAt
+	if err := s.payments.CheckMethod(newTrx); err != nil {
+		return nil, err
+	}
+	// Pesanan COD dibayar saat diterima, jadi langsung diproses seller.
+	if newTrx.MethodBayar == models.PaymentMethodCOD {
+		newTrx.Status = models.TrxStatusProcessing
+		newTrx.ExpiresAt = nil
+	}
+	for i := range newTrx.TrxToko {
+		sub := &newTrx.TrxToko[i]
+		sub.KodeInvoice = fmt.Sprintf("%s-%d", newTrx.KodeInvoice, sub.IDToko)
+		sub.Status = newTrx.Status
+	}
+
+	err = s.trxRepo.Create(newTrx)
+	if err != nil {
+		return nil, err
+	}
+	// Transaksi baru menjadi paid lewat callback provider pembayaran.
+	// Tagihan dibuat setelah transaksi tersimpan; bila gagal, transaksi
+	// langsung dibatalkan, dan kalaupun pembatalan gagal reaper akan
+	// membatalkannya setelah ExpiresAt.
+	if err := s.payments.CreateCharge(newTrx); err != nil {
+		if _, cancelErr := s.trxRepo.CancelPending(newTrx); cancelErr != nil {
+			log.Printf("Gagal membatalkan transaksi %s tanpa tagihan: %v", newTrx.KodeInvoice, cancelErr)
+		}
+		return nil, err
+	}
+	s.stockAlert.Evaluate(productIDs(newTrx)...)
+
+	return newTrx, nil
+}
+
+// newKodeInvoice membuat kode invoice dengan tanggal dan 40 bit acak dari
+// crypto/rand, sehingga praktis tidak mungkin sama dengan invoice lain.
+func newKodeInvoice(now time.Time) (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("INV-%s-%X", now.Format("20060102"), b), nil
+}
+
+// priceTrx menyusun transaksi beserta harga setiap item, potongan voucher
+// dan ongkir dari payload, tanpa menyimpannya.
+func (s *trxServiceImpl) priceTrx(userID uint, payload *models.TrxPayload) (*models.Trx, error) {
 	var totalHarga int
 	var detailTrxList []models.DetailTrx
 
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	isReseller := user != nil && user.ResellerStatus == models.ResellerStatusApproved
+
 	for _, item := range payload.DetailTrx {
+		if item.Kuantitas <= 0 {
+			return nil, fmt.Errorf("kuantitas for product with ID %d must be positive", item.ProductID)
+		}
+
 		product, err := s.productRepo.FindByID(item.ProductID)
 		if err != nil || product == nil {
 			return nil, fmt.Errorf("product with ID %d not found", item.ProductID)
 		}
+		if product.Status != models.ProductPublished {
+			return nil, fmt.Errorf("product %s is not available", product.NamaProduct)
+		}
 
-		if product.Stok < item.Kuantitas {
+		var variant *models.ProductVariant
+		stok := product.Stok
+		if len(product.ProductVariant) > 0 {
+			variant = findVariant(product, item.VariantID)
+			if variant == nil {
+				return nil, fmt.Errorf("a valid variant_id is required for product %s", product.NamaProduct)
+			}
+			stok = variant.Stok
+		} else if item.VariantID != nil {
+			return nil, fmt.Errorf("product %s has no variants", product.NamaProduct)
+		}
+
+		if stok < item.Kuantitas {
 			return nil, fmt.Errorf("stock for product %s is insufficient", product.NamaProduct)
 		}
 
-		itemTotal := product.HargaKonsumen * item.Kuantitas
+		active, err := s.discounts.ActiveFor(product)
+		if err != nil {
+			return nil, err
+		}
+		hargaSatuan, tierHarga, discount := unitPrice(product, variant, isReseller, item.Kuantitas, active[product.ID])
+		itemTotal := hargaSatuan * item.Kuantitas
 		totalHarga += itemTotal
 
-		detailTrxList = append(detailTrxList, models.DetailTrx{
+		// Potongan dihitung terhadap harga terbaik tanpa diskon supaya
+		// tidak ikut menghitung selisih harga reseller atau grosir.
+		var idDiscount *uint
+		var potongan int
+		if discount != nil {
+			hargaNormal, _, _ := unitPrice(product, variant, isReseller, item.Kuantitas, nil)
+			idDiscount = &discount.ID
+			potongan = (hargaNormal - hargaSatuan) * item.Kuantitas
+		}
 
-			IDToko:     product.IDToko,
-			Kuantitas:  item.Kuantitas,
-			HargaTotal: itemTotal,
+		detailTrxList = append(detailTrxList, models.DetailTrx{
+			ProductID:   product.ID,
+			VariantID:   item.VariantID,
+			IDToko:      product.IDToko,
+			Kuantitas:   item.Kuantitas,
+			HargaSatuan: hargaSatuan,
+			TierHarga:   tierHarga,
+			HargaTotal:  itemTotal,
+			IDDiscount:  idDiscount,
+			Potongan:    potongan,
+			ProductLog: models.ProductLog{
+				ProductID:     product.ID,
+				IDToko:        product.IDToko,
+				IDCategory:    product.IDCategory,
+				NamaProduct:   product.NamaProduct,
+				Slug:          product.Slug,
+				HargaReseller: product.HargaReseller,
+				HargaKonsumen: product.HargaKonsumen,
+				Deskripsi:     product.Deskripsi,
+			},
 		})
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	kodeInvoice := fmt.Sprintf("INV-%d", rand.Intn(1000000))
-
-	newTrx := &models.Trx{
+	trx := &models.Trx{
 		IDUser:           userID,
 		AlamatPengiriman: payload.AlamatKirim,
-		KodeInvoice:      kodeInvoice,
 		MethodBayar:      payload.MethodBayar,
 		HargaTotal:       totalHarga,
 		DetailTrx:        detailTrxList,
 	}
 
-	err := s.trxRepo.Create(newTrx)
-	if err != nil {
-		return nil, err
+	var voucher *models.Voucher
+	if strings.TrimSpace(payload.VoucherCode) != "" {
+		var redemption *models.VoucherRedemption
+		voucher, redemption, err = s.vouchers.Apply(userID, payload.VoucherCode, detailTrxList)
+		if err != nil {
+			return nil, err
+		}
+		trx.KodeVoucher = voucher.Kode
+		trx.PotonganVoucher = redemption.Potongan
+		trx.HargaTotal -= redemption.Potongan
+		trx.VoucherRedemption = redemption
 	}
+	trx.TrxToko = splitByToko(detailTrxList, voucher, trx.PotonganVoucher)
 
-	return newTrx, nil
+	// Voucher tidak memotong ongkir, jadi ongkir ditambahkan terakhir.
+	if strings.TrimSpace(payload.Kurir) != "" {
+		option, err := s.shipping.Quote(userID, payload.AlamatKirim, payload.Kurir, payload.DetailTrx)
+		if err != nil {
+			return nil, err
+		}
+		trx.Kurir = option.Kurir
+		trx.Ongkir = option.Ongkir
+		trx.HargaTotal += option.Ongkir
+		for _, quote := range option.PerToko {
+			for i := range trx.TrxToko {
+				if sub := &trx.TrxToko[i]; sub.IDToko == quote.IDToko {
+					sub.Ongkir = quote.Ongkir
+					sub.EstimasiKirim = quote.Estimasi
+					sub.HargaTotal += quote.Ongkir
+				}
+			}
+		}
+	}
+	return trx, nil
+}
+
+// splitByToko membuat satu sub-order untuk setiap toko sesuai urutan item.
+// Potongan voucher toko dibebankan seluruhnya ke toko penerbitnya,
+// sedangkan potongan voucher admin dibagi proporsional terhadap subtotal
+// setiap toko dengan sisa pembulatan di toko terakhir.
+func splitByToko(details []models.DetailTrx, voucher *models.Voucher, potongan int) []models.TrxToko {
+	var subOrders []models.TrxToko
+	index := map[uint]int{}
+	var eligible int
+	for _, detail := range details {
+		i, ok := index[detail.IDToko]
+		if !ok {
+			i = len(subOrders)
+			index[detail.IDToko] = i
+			subOrders = append(subOrders, models.TrxToko{IDToko: detail.IDToko})
+		}
+		subOrders[i].Subtotal += detail.HargaTotal
+		if voucher != nil && (voucher.IDToko == nil || *voucher.IDToko == detail.IDToko) {
+			eligible += detail.HargaTotal
+		}
+	}
+
+	remaining := potongan
+	for i := range subOrders {
+		sub := &subOrders[i]
+		if voucher != nil && eligible > 0 && (voucher.IDToko == nil || *voucher.IDToko == sub.IDToko) {
+			share := potongan * sub.Subtotal / eligible
+			if voucher.IDToko != nil || i == len(subOrders)-1 {
+				share = remaining
+			}
+			sub.PotonganVoucher = share
+			remaining -= share
+		}
+		sub.HargaTotal = sub.Subtotal - sub.PotonganVoucher
+	}
+	return subOrders
+}
+
+// ReleaseExpiredReservations membatalkan transaksi yang tidak dibayar
+// sampai reservasinya habis. Dijalankan berkala oleh reaper di main.
+func (s *trxServiceImpl) ReleaseExpiredReservations() error {
+	cancelled, err := s.trxRepo.CancelExpired(time.Now())
+	for _, trx := range cancelled {
+		log.Printf("Membatalkan transaksi %s yang tidak dibayar", trx.KodeInvoice)
+		s.stockAlert.Evaluate(productIDs(&trx)...)
+	}
+	return err
+}
+
+func productIDs(trx *models.Trx) []uint {
+	ids := make([]uint, 0, len(trx.DetailTrx))
+	seen := map[uint]bool{}
+	for _, detail := range trx.DetailTrx {
+		if !seen[detail.ProductID] {
+			seen[detail.ProductID] = true
+			ids = append(ids, detail.ProductID)
+		}
+	}
+	return ids
+}
+
+func findVariant(product *models.Product, variantID *uint) *models.ProductVariant {
+	if variantID == nil {
+		return nil
+	}
+	for i := range product.ProductVariant {
+		if product.ProductVariant[i].ID == *variantID {
+			return &product.ProductVariant[i]
+		}
+	}
+	return nil
 }
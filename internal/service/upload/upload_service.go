@@ -0,0 +1,189 @@
+package upload_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"test-rakamin/internal/models"
+	stored_file_repository "test-rakamin/internal/repository/stored_file"
+	"test-rakamin/pkg/signedurl"
+	"test-rakamin/pkg/storage"
+	"test-rakamin/utils"
+)
+
+type UploadService interface {
+	SaveUploadedFile(file *multipart.FileHeader) (string, error)
+	SaveImage(data []byte) (string, error)
+	SaveImageFromURL(rawURL string) (string, error)
+	Release(key string) error
+	Variants(key string) map[string]string
+	CollectGarbage(minAge time.Duration, dryRun bool) ([]string, error)
+}
+
+type uploadServiceImpl struct {
+	storedFileRepo stored_file_repository.StoredFileRepository
+	storage        storage.Storage
+}
+
+func NewUploadService(repo stored_file_repository.StoredFileRepository, store storage.Storage) UploadService {
+	return &uploadServiceImpl{storedFileRepo: repo, storage: store}
+}
+
+func (s *uploadServiceImpl) SaveUploadedFile(file *multipart.FileHeader) (string, error) {
+	data, err := utils.ReadUploadedFile(file)
+	if err != nil {
+		return "", err
+	}
+	return s.SaveImage(data)
+}
+
+// SaveImageFromURL mengunduh gambar dari rawURL lalu menyimpannya seperti
+// SaveImage.
+func (s *uploadServiceImpl) SaveImageFromURL(rawURL string) (string, error) {
+	data, err := utils.DownloadImage(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return s.SaveImage(data)
+}
+
+// SaveImage menyimpan gambar dengan key berupa hash SHA-256 isinya. Bila
+// isi yang sama sudah pernah diunggah, file lama dipakai ulang dan jumlah
+// referensinya ditambah. Setiap pemanggilan harus diimbangi Release saat
+// referensinya dihapus.
+func (s *uploadServiceImpl) SaveImage(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	key, err := s.reuse(hash)
+	if err != nil || key != "" {
+		return key, err
+	}
+
+	key, err = utils.SaveImage(s.storage, hash, data)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.storedFileRepo.Create(&models.StoredFile{Hash: hash, Key: key, RefCount: 1}); err != nil {
+		// Upload paralel dengan isi yang sama bisa lebih dulu membuat baris
+		// ini; cukup tambahkan referensinya.
+		existing, reuseErr := s.reuse(hash)
+		if reuseErr != nil || existing == "" {
+			return "", err
+		}
+		return existing, nil
+	}
+	return key, nil
+}
+
+// reuse menambah referensi file dengan hash yang sama bila ada. Key kosong
+// berarti file belum ada atau baru saja dihapus garbage collector.
+func (s *uploadServiceImpl) reuse(hash string) (string, error) {
+	existing, err := s.storedFileRepo.FindByHash(hash)
+	if err != nil || existing == nil {
+		return "", err
+	}
+	found, err := s.storedFileRepo.IncrementRef(existing.Key)
+	if err != nil || !found {
+		return "", err
+	}
+	return existing.Key, nil
+}
+
+// Release mengurangi jumlah referensi key. File tidak langsung dihapus;
+// penghapusan dilakukan oleh CollectGarbage.
+func (s *uploadServiceImpl) Release(key string) error {
+	if key == "" {
+		return nil
+	}
+	return s.storedFileRepo.DecrementRef(key)
+}
+
+func (s *uploadServiceImpl) Variants(key string) map[string]string {
+	return utils.UploadVariants(s.storage, key)
+}
+
+// CollectGarbage menghapus file yang tidak lagi direferensikan dan tidak
+// diubah selama minAge. minAge memberi jeda bagi upload yang barisnya belum
+// sempat dibuat. File dengan baris StoredFile dihitung ulang referensinya;
+// file lain di storage, termasuk upload lama yang dinamai timestamp,
+// dibandingkan langsung dengan key yang masih dipakai. File private tidak
+// pernah disentuh.
+func (s *uploadServiceImpl) CollectGarbage(minAge time.Duration, dryRun bool) ([]string, error) {
+	cutoff := time.Now().Add(-minAge)
+	referenced, err := s.storedFileRepo.ReferencedKeys()
+	if err != nil {
+		return nil, err
+	}
+	inUse := map[string]bool{}
+	for _, key := range referenced {
+		for _, variant := range utils.UploadVariantKeys(key) {
+			inUse[variant] = true
+		}
+	}
+
+	files, err := s.storedFileRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	tracked := map[string]bool{}
+	for _, file := range files {
+		for _, variant := range utils.UploadVariantKeys(file.Key) {
+			tracked[variant] = true
+		}
+		refs, err := s.storedFileRepo.CountReferences(file.Key)
+		if err != nil {
+			return removed, err
+		}
+		if refs > 0 || file.UpdatedAt.After(cutoff) {
+			if int(refs) != file.RefCount && !dryRun {
+				if err := s.storedFileRepo.UpdateRefCount(file.ID, int(refs)); err != nil {
+					return removed, err
+				}
+			}
+			continue
+		}
+
+		if dryRun {
+			removed = append(removed, file.Key)
+			continue
+		}
+		deleted, err := s.storedFileRepo.DeleteUnreferenced(file.ID, cutoff, s.deleteObjects)
+		if err != nil {
+			return removed, err
+		}
+		if deleted {
+			removed = append(removed, file.Key)
+		}
+	}
+
+	err = s.storage.List(func(key string, modTime time.Time) error {
+		if tracked[key] || inUse[key] || signedurl.IsPrivate(key) || modTime.After(cutoff) {
+			return nil
+		}
+		removed = append(removed, key)
+		if dryRun {
+			return nil
+		}
+		if err := s.storage.Delete(key); err != nil {
+			log.Printf("gagal menghapus %s: %v", key, err)
+		}
+		return nil
+	})
+	return removed, err
+}
+
+func (s *uploadServiceImpl) deleteObjects(key string) error {
+	for _, variant := range utils.UploadVariantKeys(key) {
+		if err := s.storage.Delete(variant); err != nil {
+			return err
+		}
+	}
+	return nil
+}
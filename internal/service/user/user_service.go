@@ -13,11 +13,16 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var ErrForbidden = errors.New("admin access required")
+
 type UserService interface {
 	RegisterUser(user *models.User) (*models.User, error)
 	LoginUser(noTelp, password string) (string, error)
 	GetUserProfile(userID uint) (*models.User, error)
 	UpdateUserProfile(userID uint, updatedUser *models.User) (*models.User, error)
+	ApplyReseller(userID uint) (*models.User, error)
+	GetResellerApplications(adminID uint, status string) ([]models.User, error)
+	ReviewResellerApplication(adminID, userID uint, approve bool) (*models.User, error)
 }
 
 type userServiceImpl struct {
@@ -44,6 +49,8 @@ func (s *userServiceImpl) RegisterUser(user *models.User) (*models.User, error)
 		return nil, errors.New("failed to encrypt password")
 	}
 	user.KataSandi = string(hashedPassword)
+	user.ResellerStatus = ""
+	user.ResellerApprovedAt = nil
 
 	err = s.userRepo.Create(user)
 	if err != nil {
@@ -126,3 +133,77 @@ func (s *userServiceImpl) UpdateUserProfile(userID uint, updatedUser *models.Use
 	}
 	return existingUser, nil
 }
+
+func (s *userServiceImpl) ApplyReseller(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	switch user.ResellerStatus {
+	case models.ResellerStatusApproved:
+		return nil, errors.New("user is already an approved reseller")
+	case models.ResellerStatusPending:
+		return nil, errors.New("reseller application is already pending")
+	}
+
+	user.ResellerStatus = models.ResellerStatusPending
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userServiceImpl) GetResellerApplications(adminID uint, status string) ([]models.User, error) {
+	if err := s.ensureAdmin(adminID); err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = models.ResellerStatusPending
+	}
+	return s.userRepo.FindByResellerStatus(status)
+}
+
+func (s *userServiceImpl) ReviewResellerApplication(adminID, userID uint, approve bool) (*models.User, error) {
+	if err := s.ensureAdmin(adminID); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	// Reseller yang sudah disetujui masih bisa dicabut lewat reject.
+	if user.ResellerStatus != models.ResellerStatusPending &&
+		(approve || user.ResellerStatus != models.ResellerStatusApproved) {
+		return nil, errors.New("user has no pending reseller application")
+	}
+
+	if approve {
+		now := time.Now()
+		user.ResellerStatus = models.ResellerStatusApproved
+		user.ResellerApprovedAt = &now
+	} else {
+		user.ResellerStatus = models.ResellerStatusRejected
+		user.ResellerApprovedAt = nil
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userServiceImpl) ensureAdmin(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
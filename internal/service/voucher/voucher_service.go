@@ -0,0 +1,248 @@
+package voucher_service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"test-rakamin/internal/models"
+	toko_repository "test-rakamin/internal/repository/toko"
+	user_repository "test-rakamin/internal/repository/user"
+	voucher_repository "test-rakamin/internal/repository/voucher"
+)
+
+var (
+	ErrForbidden            = errors.New("you are not allowed to manage this voucher")
+	ErrInvalidVoucher       = errors.New("invalid voucher")
+	ErrNotFound             = errors.New("voucher not found")
+	ErrVoucherNotApplicable = errors.New("voucher cannot be applied")
+)
+
+var kodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,50}$`)
+
+type VoucherService interface {
+	GetMyVouchers(userID uint) ([]models.Voucher, error)
+	CreateVoucher(userID uint, payload *models.VoucherPayload) (*models.Voucher, error)
+	DeleteVoucher(userID, id uint) error
+	Apply(userID uint, kode string, details []models.DetailTrx) (*models.Voucher, *models.VoucherRedemption, error)
+}
+
+type voucherServiceImpl struct {
+	voucherRepo voucher_repository.VoucherRepository
+	tokoRepo    toko_repository.TokoRepository
+	userRepo    user_repository.UserRepository
+}
+
+func NewVoucherService(repo voucher_repository.VoucherRepository, tokoRepo toko_repository.TokoRepository, userRepo user_repository.UserRepository) VoucherService {
+	return &voucherServiceImpl{voucherRepo: repo, tokoRepo: tokoRepo, userRepo: userRepo}
+}
+
+func (s *voucherServiceImpl) GetMyVouchers(userID uint) ([]models.Voucher, error) {
+	return s.voucherRepo.FindByUserID(userID)
+}
+
+// CreateVoucher membuat voucher toko bila id_toko diisi, hanya untuk
+// pemilik toko tersebut atau admin. Voucher tanpa id_toko hanya bisa
+// dibuat admin.
+func (s *voucherServiceImpl) CreateVoucher(userID uint, payload *models.VoucherPayload) (*models.Voucher, error) {
+	voucher := &models.Voucher{
+		Kode:         normalizeKode(payload.Kode),
+		IDUser:       userID,
+		IDToko:       payload.IDToko,
+		Tipe:         strings.ToLower(payload.Tipe),
+		Nilai:        payload.Nilai,
+		MinBelanja:   payload.MinBelanja,
+		MaksPotongan: payload.MaksPotongan,
+		Kuota:        payload.Kuota,
+		KuotaPerUser: payload.KuotaPerUser,
+		MulaiAt:      payload.MulaiAt,
+		SelesaiAt:    payload.SelesaiAt,
+	}
+	if err := validateVoucher(voucher); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCanIssue(userID, voucher.IDToko); err != nil {
+		return nil, err
+	}
+
+	// Kode voucher yang sudah dihapus tetap tidak bisa dipakai lagi supaya
+	// riwayat redemption tidak tertukar.
+	existing, err := s.voucherRepo.FindByKode(voucher.Kode, true)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: kode %s is already used", ErrInvalidVoucher, voucher.Kode)
+	}
+
+	if err := s.voucherRepo.Create(voucher); err != nil {
+		return nil, err
+	}
+	return voucher, nil
+}
+
+func normalizeKode(kode string) string {
+	return strings.ToUpper(strings.TrimSpace(kode))
+}
+
+func validateVoucher(voucher *models.Voucher) error {
+	if !kodePattern.MatchString(voucher.Kode) {
+		return fmt.Errorf("%w: kode must be 3-50 letters, digits, - or _", ErrInvalidVoucher)
+	}
+	switch voucher.Tipe {
+	case models.DiscountPercent:
+		if voucher.Nilai < 1 || voucher.Nilai > 100 {
+			return fmt.Errorf("%w: nilai for percent voucher must be between 1 and 100", ErrInvalidVoucher)
+		}
+	case models.DiscountFixed:
+		if voucher.Nilai <= 0 {
+			return fmt.Errorf("%w: nilai must be positive", ErrInvalidVoucher)
+		}
+	default:
+		return fmt.Errorf("%w: tipe must be %s or %s", ErrInvalidVoucher, models.DiscountPercent, models.DiscountFixed)
+	}
+	if voucher.MinBelanja < 0 {
+		return fmt.Errorf("%w: min_belanja must not be negative", ErrInvalidVoucher)
+	}
+	if voucher.MaksPotongan != nil && *voucher.MaksPotongan <= 0 {
+		return fmt.Errorf("%w: maks_potongan must be positive", ErrInvalidVoucher)
+	}
+	if voucher.Kuota != nil && *voucher.Kuota <= 0 {
+		return fmt.Errorf("%w: kuota must be positive", ErrInvalidVoucher)
+	}
+	if voucher.KuotaPerUser != nil && *voucher.KuotaPerUser <= 0 {
+		return fmt.Errorf("%w: kuota_per_user must be positive", ErrInvalidVoucher)
+	}
+
+	// mulai_at kosong berarti voucher langsung berlaku.
+	if voucher.MulaiAt.IsZero() {
+		voucher.MulaiAt = time.Now()
+	}
+	if !voucher.SelesaiAt.After(voucher.MulaiAt) {
+		return fmt.Errorf("%w: selesai_at must be after mulai_at", ErrInvalidVoucher)
+	}
+	if !voucher.SelesaiAt.After(time.Now()) {
+		return fmt.Errorf("%w: selesai_at must be in the future", ErrInvalidVoucher)
+	}
+	return nil
+}
+
+func (s *voucherServiceImpl) ensureCanIssue(userID uint, tokoID *uint) error {
+	isAdmin, err := s.isAdmin(userID)
+	if err != nil {
+		return err
+	}
+	if tokoID == nil {
+		if !isAdmin {
+			return ErrForbidden
+		}
+		return nil
+	}
+
+	toko, err := s.tokoRepo.FindByID(*tokoID)
+	if err != nil {
+		return err
+	}
+	if toko == nil {
+		return fmt.Errorf("%w: toko %d not found", ErrInvalidVoucher, *tokoID)
+	}
+	if !isAdmin && toko.IDUser != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *voucherServiceImpl) isAdmin(userID uint) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsAdmin, nil
+}
+
+// DeleteVoucher menghentikan voucher dan hanya bisa dilakukan pembuatnya
+// atau admin. Transaksi yang sudah memakai voucher tidak berubah.
+func (s *voucherServiceImpl) DeleteVoucher(userID, id uint) error {
+	voucher, err := s.voucherRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if voucher == nil {
+		return ErrNotFound
+	}
+	if voucher.IDUser != userID {
+		isAdmin, err := s.isAdmin(userID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return ErrForbidden
+		}
+	}
+	return s.voucherRepo.Delete(id)
+}
+
+// Apply memeriksa apakah voucher kode bisa dipakai userID untuk details
+// dan menghitung potongannya. Voucher toko hanya dihitung dari item toko
+// tersebut. Kuota baru benar-benar dipakai saat transaksi disimpan.
+func (s *voucherServiceImpl) Apply(userID uint, kode string, details []models.DetailTrx) (*models.Voucher, *models.VoucherRedemption, error) {
+	kode = normalizeKode(kode)
+	voucher, err := s.voucherRepo.FindByKode(kode, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if voucher == nil {
+		return nil, nil, fmt.Errorf("%w: voucher %s not found", ErrVoucherNotApplicable, kode)
+	}
+
+	now := time.Now()
+	if now.Before(voucher.MulaiAt) || !now.Before(voucher.SelesaiAt) {
+		return nil, nil, fmt.Errorf("%w: voucher %s is not active", ErrVoucherNotApplicable, kode)
+	}
+	if voucher.Kuota != nil && voucher.Terpakai >= *voucher.Kuota {
+		return nil, nil, fmt.Errorf("%w: voucher %s has been fully used", ErrVoucherNotApplicable, kode)
+	}
+	if voucher.KuotaPerUser != nil {
+		count, err := s.voucherRepo.CountRedeemed(voucher.ID, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if count >= int64(*voucher.KuotaPerUser) {
+			return nil, nil, fmt.Errorf("%w: you have used voucher %s the maximum number of times", ErrVoucherNotApplicable, kode)
+		}
+	}
+
+	var subtotal int
+	for _, detail := range details {
+		if voucher.IDToko == nil || detail.IDToko == *voucher.IDToko {
+			subtotal += detail.HargaTotal
+		}
+	}
+	if subtotal == 0 {
+		return nil, nil, fmt.Errorf("%w: voucher %s does not apply to any item", ErrVoucherNotApplicable, kode)
+	}
+	if subtotal < voucher.MinBelanja {
+		return nil, nil, fmt.Errorf("%w: minimum spend for voucher %s is %d", ErrVoucherNotApplicable, kode, voucher.MinBelanja)
+	}
+
+	return voucher, &models.VoucherRedemption{
+		IDVoucher: voucher.ID,
+		IDUser:    userID,
+		Potongan:  potongan(voucher, subtotal),
+		Status:    models.VoucherRedeemed,
+	}, nil
+}
+
+// potongan tidak pernah melebihi MaksPotongan maupun subtotal.
+func potongan(voucher *models.Voucher, subtotal int) int {
+	amount := voucher.Nilai
+	if voucher.Tipe == models.DiscountPercent {
+		amount = subtotal * voucher.Nilai / 100
+	}
+	if voucher.MaksPotongan != nil {
+		amount = min(amount, *voucher.MaksPotongan)
+	}
+	return min(amount, subtotal)
+}
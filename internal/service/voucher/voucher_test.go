@@ -0,0 +1,32 @@
+package voucher_service
+
+import (
+	"testing"
+
+	"test-rakamin/internal/models"
+)
+
+func TestPotongan(t *testing.T) {
+	maks := 15000
+	tests := []struct {
+		name     string
+		voucher  models.Voucher
+		subtotal int
+		want     int
+	}{
+		{name: "persen", voucher: models.Voucher{Tipe: models.DiscountPercent, Nilai: 10}, subtotal: 100000, want: 10000},
+		{name: "persen dibulatkan ke bawah", voucher: models.Voucher{Tipe: models.DiscountPercent, Nilai: 15}, subtotal: 9999, want: 1499},
+		{name: "persen dibatasi maks potongan", voucher: models.Voucher{Tipe: models.DiscountPercent, Nilai: 50, MaksPotongan: &maks}, subtotal: 100000, want: 15000},
+		{name: "nominal", voucher: models.Voucher{Tipe: models.DiscountFixed, Nilai: 20000}, subtotal: 100000, want: 20000},
+		{name: "nominal dibatasi maks potongan", voucher: models.Voucher{Tipe: models.DiscountFixed, Nilai: 20000, MaksPotongan: &maks}, subtotal: 100000, want: 15000},
+		{name: "tidak melebihi subtotal", voucher: models.Voucher{Tipe: models.DiscountFixed, Nilai: 20000}, subtotal: 12000, want: 12000},
+		{name: "persen penuh", voucher: models.Voucher{Tipe: models.DiscountPercent, Nilai: 100}, subtotal: 12000, want: 12000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := potongan(&tt.voucher, tt.subtotal); got != tt.want {
+				t.Errorf("potongan = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
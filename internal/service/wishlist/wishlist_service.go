@@ -0,0 +1,87 @@
+package wishlist_service
+
+import (
+	"errors"
+
+	"test-rakamin/internal/models"
+	product_repository "test-rakamin/internal/repository/product"
+	wishlist_repository "test-rakamin/internal/repository/wishlist"
+	product_service "test-rakamin/internal/service/product"
+)
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrNotInWishlist   = errors.New("product is not in wishlist")
+)
+
+type WishlistService interface {
+	GetWishlist(userID uint) ([]models.Wishlist, error)
+	AddToWishlist(userID, productID uint) error
+	RemoveFromWishlist(userID, productID uint) error
+}
+
+type wishlistServiceImpl struct {
+	wishlistRepo   wishlist_repository.WishlistRepository
+	productRepo    product_repository.ProductRepository
+	productService product_service.ProductService
+}
+
+func NewWishlistService(repo wishlist_repository.WishlistRepository, productRepo product_repository.ProductRepository, productService product_service.ProductService) WishlistService {
+	return &wishlistServiceImpl{wishlistRepo: repo, productRepo: productRepo, productService: productService}
+}
+
+// GetWishlist mengembalikan wishlist terbaru lebih dulu beserta harga dan
+// stok tersedia produk saat ini. Produk yang sudah dihapus tidak ikut
+// ditampilkan.
+func (s *wishlistServiceImpl) GetWishlist(userID uint) ([]models.Wishlist, error) {
+	wishlists, err := s.wishlistRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(wishlists))
+	for _, wishlist := range wishlists {
+		ids = append(ids, wishlist.ProductID)
+	}
+	products, err := s.productService.GetProductsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		product.IsWishlisted = true
+		byID[product.ID] = product
+	}
+
+	result := make([]models.Wishlist, 0, len(wishlists))
+	for _, wishlist := range wishlists {
+		product, ok := byID[wishlist.ProductID]
+		if !ok {
+			continue
+		}
+		wishlist.Product = product
+		result = append(result, wishlist)
+	}
+	return result, nil
+}
+
+func (s *wishlistServiceImpl) AddToWishlist(userID, productID uint) error {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return err
+	}
+	if product == nil || product.Status != models.ProductPublished {
+		return ErrProductNotFound
+	}
+	return s.wishlistRepo.Add(userID, productID)
+}
+
+func (s *wishlistServiceImpl) RemoveFromWishlist(userID, productID uint) error {
+	found, err := s.wishlistRepo.Remove(userID, productID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotInWishlist
+	}
+	return nil
+}
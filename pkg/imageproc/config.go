@@ -0,0 +1,108 @@
+package imageproc
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Thumbnail mendefinisikan satu ukuran turunan gambar. Gambar diperkecil
+// hingga muat di dalam kotak Width x Height dengan rasio tetap.
+type Thumbnail struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+type Config struct {
+	MaxBytes    int64
+	MaxWidth    int
+	MaxHeight   int
+	JPEGQuality int
+	Thumbnails  []Thumbnail
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxBytes:    5 << 20,
+		MaxWidth:    6000,
+		MaxHeight:   6000,
+		JPEGQuality: 85,
+		Thumbnails: []Thumbnail{
+			{Name: "small", Width: 150, Height: 150},
+			{Name: "medium", Width: 480, Height: 480},
+			{Name: "large", Width: 1024, Height: 1024},
+		},
+	}
+}
+
+// LoadConfigFromEnv membaca batas upload dari environment dan memakai
+// DefaultConfig untuk nilai yang tidak di-set.
+//
+//	UPLOAD_MAX_BYTES=5242880
+//	UPLOAD_MAX_WIDTH=6000
+//	UPLOAD_MAX_HEIGHT=6000
+//	UPLOAD_JPEG_QUALITY=85
+//	UPLOAD_THUMBNAILS=small:150x150,medium:480x480,large:1024x1024
+func LoadConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+
+	if v := os.Getenv("UPLOAD_MAX_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("UPLOAD_MAX_BYTES tidak valid: %q", v)
+		}
+		cfg.MaxBytes = n
+	}
+	for env, dst := range map[string]*int{
+		"UPLOAD_MAX_WIDTH":    &cfg.MaxWidth,
+		"UPLOAD_MAX_HEIGHT":   &cfg.MaxHeight,
+		"UPLOAD_JPEG_QUALITY": &cfg.JPEGQuality,
+	} {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return cfg, fmt.Errorf("%s tidak valid: %q", env, v)
+			}
+			*dst = n
+		}
+	}
+	if cfg.JPEGQuality > 100 {
+		return cfg, fmt.Errorf("UPLOAD_JPEG_QUALITY harus di antara 1 dan 100")
+	}
+
+	if v := os.Getenv("UPLOAD_THUMBNAILS"); v != "" {
+		thumbs, err := parseThumbnails(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Thumbnails = thumbs
+	}
+	return cfg, nil
+}
+
+func parseThumbnails(spec string) ([]Thumbnail, error) {
+	var thumbs []Thumbnail
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, size, ok := strings.Cut(part, ":")
+		if !ok || name == "" || name == "original" {
+			return nil, fmt.Errorf("UPLOAD_THUMBNAILS tidak valid: %q", part)
+		}
+		w, h, ok := strings.Cut(size, "x")
+		if !ok {
+			h = w
+		}
+		width, errW := strconv.Atoi(w)
+		height, errH := strconv.Atoi(h)
+		if errW != nil || errH != nil || width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("UPLOAD_THUMBNAILS tidak valid: %q", part)
+		}
+		thumbs = append(thumbs, Thumbnail{Name: name, Width: width, Height: height})
+	}
+	return thumbs, nil
+}
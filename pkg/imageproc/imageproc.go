@@ -0,0 +1,143 @@
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupportedType = errors.New("tipe file tidak didukung, hanya JPEG, PNG, atau WebP")
+	ErrTooLarge        = errors.New("ukuran file melebihi batas")
+	ErrTooManyPixels   = errors.New("dimensi gambar melebihi batas")
+)
+
+// Result adalah hasil pemrosesan satu gambar. Original berisi gambar yang
+// sudah di-encode ulang (tanpa metadata EXIF), Variants berisi thumbnail
+// dengan key sesuai Thumbnail.Name.
+type Result struct {
+	Ext         string
+	ContentType string
+	Width       int
+	Height      int
+	Original    []byte
+	Variants    map[string][]byte
+}
+
+// Process memvalidasi isi file berdasarkan sniffing MIME (bukan ekstensi
+// atau header dari client), memeriksa batas ukuran dan dimensi, lalu
+// meng-encode ulang gambar dan membuat thumbnail sesuai cfg.
+func Process(r io.Reader, cfg Config) (*Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, cfg.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file: %w", err)
+	}
+	if int64(len(data)) > cfg.MaxBytes {
+		return nil, fmt.Errorf("%w (maksimal %d byte)", ErrTooLarge, cfg.MaxBytes)
+	}
+
+	mime := http.DetectContentType(data)
+	var decode func(io.Reader) (image.Image, error)
+	var decodeConfig func(io.Reader) (image.Config, error)
+	switch mime {
+	case "image/jpeg":
+		decode, decodeConfig = jpeg.Decode, jpeg.DecodeConfig
+	case "image/png":
+		decode, decodeConfig = png.Decode, png.DecodeConfig
+	case "image/webp":
+		decode, decodeConfig = webp.Decode, webp.DecodeConfig
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, mime)
+	}
+
+	// Cek dimensi sebelum decode penuh supaya gambar raksasa tidak sempat
+	// dialokasikan ke memori.
+	imgCfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if imgCfg.Width > cfg.MaxWidth || imgCfg.Height > cfg.MaxHeight {
+		return nil, fmt.Errorf("%w (%dx%d, maksimal %dx%d)", ErrTooManyPixels,
+			imgCfg.Width, imgCfg.Height, cfg.MaxWidth, cfg.MaxHeight)
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if mime == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	// PNG dan WebP transparan disimpan sebagai PNG agar alpha tidak hilang,
+	// selain itu disimpan sebagai JPEG.
+	encodePNG := mime == "image/png" || (mime == "image/webp" && !isOpaque(img))
+	encode := func(m image.Image) ([]byte, error) {
+		var buf bytes.Buffer
+		var err error
+		if encodePNG {
+			err = png.Encode(&buf, m)
+		} else {
+			err = jpeg.Encode(&buf, m, &jpeg.Options{Quality: cfg.JPEGQuality})
+		}
+		return buf.Bytes(), err
+	}
+
+	res := &Result{
+		Ext:         ".jpg",
+		ContentType: "image/jpeg",
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Variants:    make(map[string][]byte, len(cfg.Thumbnails)),
+	}
+	if encodePNG {
+		res.Ext, res.ContentType = ".png", "image/png"
+	}
+
+	if res.Original, err = encode(img); err != nil {
+		return nil, fmt.Errorf("gagal meng-encode gambar: %w", err)
+	}
+	for _, t := range cfg.Thumbnails {
+		b, err := encode(resize(img, t.Width, t.Height))
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuat thumbnail %s: %w", t.Name, err)
+		}
+		res.Variants[t.Name] = b
+	}
+	return res, nil
+}
+
+// resize memperkecil img agar muat di dalam maxW x maxH dengan rasio tetap.
+// Gambar yang sudah lebih kecil tidak diperbesar.
+func resize(img image.Image, maxW, maxH int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxW && h <= maxH {
+		return img
+	}
+	if w*maxH > h*maxW {
+		h = max(1, h*maxW/w)
+		w = maxW
+	} else {
+		w = max(1, w*maxH/h)
+		h = maxH
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func solidImage(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeGIF(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation menyisipkan segmen APP1 EXIF berisi tag Orientation
+// setelah marker SOI.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	entry := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(entry[0:], 1)
+	binary.LittleEndian.PutUint16(entry[2:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[4:], 3)
+	binary.LittleEndian.PutUint32(entry[6:], 1)
+	binary.LittleEndian.PutUint16(entry[10:], orientation)
+	payload := append([]byte("Exif\x00\x00"), append(tiff, entry...)...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestProcess(t *testing.T) {
+	cfg := Config{
+		MaxBytes:    1 << 20,
+		MaxWidth:    500,
+		MaxHeight:   500,
+		JPEGQuality: 80,
+		Thumbnails:  []Thumbnail{{Name: "small", Width: 100, Height: 100}},
+	}
+	opaque := color.NRGBA{R: 200, G: 50, B: 50, A: 255}
+	transparent := color.NRGBA{R: 200, G: 50, B: 50, A: 100}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		cfg         Config
+		wantErr     error
+		wantType    string
+		wantSize    [2]int
+		wantVariant [2]int
+	}{
+		{
+			name:        "jpeg",
+			data:        encodeJPEG(t, solidImage(400, 200, opaque)),
+			wantType:    "image/jpeg",
+			wantSize:    [2]int{400, 200},
+			wantVariant: [2]int{100, 50},
+		},
+		{
+			name:        "png tetap png",
+			data:        encodePNG(t, solidImage(200, 400, transparent)),
+			wantType:    "image/png",
+			wantSize:    [2]int{200, 400},
+			wantVariant: [2]int{50, 100},
+		},
+		{
+			name:        "thumbnail tidak diperbesar",
+			data:        encodeJPEG(t, solidImage(60, 40, opaque)),
+			wantType:    "image/jpeg",
+			wantSize:    [2]int{60, 40},
+			wantVariant: [2]int{60, 40},
+		},
+		{
+			name:        "orientasi exif diterapkan",
+			data:        withOrientation(encodeJPEG(t, solidImage(300, 100, opaque)), 6),
+			wantType:    "image/jpeg",
+			wantSize:    [2]int{100, 300},
+			wantVariant: [2]int{33, 100},
+		},
+		{
+			name:    "gif ditolak",
+			data:    encodeGIF(t, solidImage(10, 10, opaque)),
+			wantErr: ErrUnsupportedType,
+		},
+		{
+			name:    "bukan gambar",
+			data:    []byte("hello world"),
+			wantErr: ErrUnsupportedType,
+		},
+		{
+			name:    "file terlalu besar",
+			data:    encodePNG(t, solidImage(50, 50, transparent)),
+			cfg:     Config{MaxBytes: 10, MaxWidth: 500, MaxHeight: 500},
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "dimensi terlalu besar",
+			data:    encodeJPEG(t, solidImage(600, 10, opaque)),
+			wantErr: ErrTooManyPixels,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cfg
+			if tt.cfg.MaxBytes != 0 {
+				c = tt.cfg
+			}
+			res, err := Process(bytes.NewReader(tt.data), c)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.ContentType != tt.wantType {
+				t.Errorf("ContentType = %s, want %s", res.ContentType, tt.wantType)
+			}
+			if res.Width != tt.wantSize[0] || res.Height != tt.wantSize[1] {
+				t.Errorf("size = %dx%d, want %dx%d", res.Width, res.Height, tt.wantSize[0], tt.wantSize[1])
+			}
+
+			thumb, _, err := image.DecodeConfig(bytes.NewReader(res.Variants["small"]))
+			if err != nil {
+				t.Fatalf("decode thumbnail: %v", err)
+			}
+			if thumb.Width != tt.wantVariant[0] || thumb.Height != tt.wantVariant[1] {
+				t.Errorf("thumbnail = %dx%d, want %dx%d", thumb.Width, thumb.Height, tt.wantVariant[0], tt.wantVariant[1])
+			}
+		})
+	}
+}
+
+func TestParseThumbnails(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []Thumbnail
+		wantErr bool
+	}{
+		{spec: "small:150x100", want: []Thumbnail{{Name: "small", Width: 150, Height: 100}}},
+		{spec: "sq:200, big:800x600", want: []Thumbnail{{Name: "sq", Width: 200, Height: 200}, {Name: "big", Width: 800, Height: 600}}},
+		{spec: "original:100", wantErr: true},
+		{spec: "small:0x10", wantErr: true},
+		{spec: "small", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseThumbnails(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package payment
+
+import "fmt"
+
+// BankTransfer adalah pembayaran lewat transfer manual ke rekening toko
+// online. Pembeli mencantumkan kode invoice di berita transfer, lalu admin
+// atau layanan mutasi rekening mengirim callback bertanda tangan setelah
+// dana diterima.
+type BankTransfer struct {
+	code     string
+	rekening string
+	secret   string
+}
+
+func NewBankTransfer(code, rekening, secret string) *BankTransfer {
+	return &BankTransfer{code: code, rekening: rekening, secret: secret}
+}
+
+func (p *BankTransfer) Code() string {
+	return p.code
+}
+
+func (p *BankTransfer) CreateCharge(req ChargeRequest) (*Charge, error) {
+	return &Charge{
+		Reference: req.KodeInvoice,
+		Instruksi: fmt.Sprintf("Transfer Rp%d ke %s dengan berita %s sebelum %s.",
+			req.Jumlah, p.rekening, req.KodeInvoice, req.ExpiresAt.Format("02-01-2006 15:04")),
+	}, nil
+}
+
+func (p *BankTransfer) VerifyCallback(body []byte, signature string) (*Callback, error) {
+	return parseSignedCallback(p.secret, body, signature)
+}
+
+// Refund tidak memindahkan dana. Dana dikembalikan admin lewat transfer
+// manual berdasarkan JumlahRefund yang tercatat.
+func (p *BankTransfer) Refund(reference string, jumlah int) error {
+	return nil
+}
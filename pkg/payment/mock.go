@@ -0,0 +1,34 @@
+package payment
+
+import "fmt"
+
+// Mock adalah provider untuk pengujian lokal. Tagihan tidak dikirim ke
+// mana pun; pembayaran disimulasikan dengan mengirim callback yang
+// ditandatangani secret yang sama ke webhook pembayaran.
+type Mock struct {
+	code   string
+	secret string
+}
+
+func NewMock(code, secret string) *Mock {
+	return &Mock{code: code, secret: secret}
+}
+
+func (p *Mock) Code() string {
+	return p.code
+}
+
+func (p *Mock) CreateCharge(req ChargeRequest) (*Charge, error) {
+	return &Charge{
+		Reference: "MOCK-" + req.KodeInvoice,
+		Instruksi: fmt.Sprintf("Simulasikan pembayaran Rp%d dengan callback ke /api/payments/webhook/%s.", req.Jumlah, p.code),
+	}, nil
+}
+
+func (p *Mock) VerifyCallback(body []byte, signature string) (*Callback, error) {
+	return parseSignedCallback(p.secret, body, signature)
+}
+
+func (p *Mock) Refund(reference string, jumlah int) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package payment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"test-rakamin/pkg/webhook"
+)
+
+var (
+	ErrInvalidSignature = errors.New("signature callback tidak valid")
+	ErrInvalidCallback  = errors.New("callback tidak valid")
+)
+
+const (
+	StatusPaid   = "paid"
+	StatusFailed = "failed"
+)
+
+// ChargeRequest adalah tagihan yang dibuat untuk satu transaksi.
+type ChargeRequest struct {
+	KodeInvoice string
+	Jumlah      int
+	ExpiresAt   time.Time
+}
+
+// Charge adalah tagihan yang sudah dibuat provider. Reference dipakai
+// provider untuk menyebut tagihan ini di callback, dan Instruksi
+// ditampilkan ke pembeli.
+type Charge struct {
+	Reference string
+	Instruksi string
+}
+
+// Callback adalah notifikasi status pembayaran dari provider. EventID unik
+// untuk setiap notifikasi dan dipakai untuk menolak replay.
+type Callback struct {
+	EventID   string `json:"event_id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Jumlah    int    `json:"jumlah"`
+}
+
+// Provider membuat tagihan, memverifikasi callback dan mengembalikan dana.
+// VerifyCallback mengembalikan ErrInvalidSignature bila signature tidak
+// cocok dan ErrInvalidCallback bila callback tidak bisa dibaca.
+type Provider interface {
+	Code() string
+	CreateCharge(req ChargeRequest) (*Charge, error)
+	VerifyCallback(body []byte, signature string) (*Callback, error)
+	Refund(reference string, jumlah int) error
+}
+
+// NewFromEnv membuat daftar payment provider yang aktif. Transfer bank
+// aktif bila PAYMENT_BANK_ACCOUNT diisi, sedangkan provider mock untuk
+// pengujian lokal aktif bila PAYMENT_MOCK_SECRET diisi.
+//
+//	PAYMENT_BANK_ACCOUNT=BCA 1234567890 a.n. PT Contoh
+//	PAYMENT_BANK_SECRET=rahasia-callback-bank
+//	PAYMENT_MOCK_SECRET=rahasia-mock
+func NewFromEnv() ([]Provider, error) {
+	var providers []Provider
+	if rekening := os.Getenv("PAYMENT_BANK_ACCOUNT"); rekening != "" {
+		secret := os.Getenv("PAYMENT_BANK_SECRET")
+		if secret == "" {
+			return nil, errors.New("PAYMENT_BANK_SECRET wajib diisi bila PAYMENT_BANK_ACCOUNT diisi")
+		}
+		providers = append(providers, NewBankTransfer("bank_transfer", rekening, secret))
+	}
+	if secret := os.Getenv("PAYMENT_MOCK_SECRET"); secret != "" {
+		providers = append(providers, NewMock("mock", secret))
+	}
+	return providers, nil
+}
+
+// parseSignedCallback memeriksa body yang ditandatangani seperti
+// webhook.Sign lalu membaca Callback di dalamnya.
+func parseSignedCallback(secret string, body []byte, signature string) (*Callback, error) {
+	if !webhook.Verify(secret, body, signature) {
+		return nil, ErrInvalidSignature
+	}
+	var callback Callback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+	return &callback, nil
+}
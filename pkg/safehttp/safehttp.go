@@ -0,0 +1,101 @@
+// Package safehttp membatasi request keluar ke URL yang diisi pengguna
+// (webhook toko, foto import) supaya server tidak bisa dipakai untuk
+// mengakses jaringan internal seperti loopback, alamat private, atau
+// metadata cloud di 169.254.169.254.
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrBlockedDestination = errors.New("tujuan URL tidak diizinkan")
+
+// blockedPrefixes melengkapi pengecekan netip untuk rentang khusus yang
+// tidak termasuk private maupun loopback.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Allowed melaporkan apakah addr adalah alamat publik yang boleh dihubungi.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL memastikan rawURL adalah URL http/https lengkap yang semua
+// alamat hasil resolusi DNS-nya publik. Dipakai saat URL disimpan; saat
+// request dikirim, Client memeriksa ulang alamat yang benar-benar dihubungi.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: URL harus http atau https", ErrBlockedDestination)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: host %s tidak bisa di-resolve", ErrBlockedDestination, u.Hostname())
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return fmt.Errorf("%w: %s mengarah ke alamat internal", ErrBlockedDestination, u.Hostname())
+		}
+	}
+	return nil
+}
+
+// NewClient membuat http.Client yang menolak koneksi ke alamat non-publik.
+// Pengecekan dilakukan di dialer setelah DNS di-resolve, sehingga redirect
+// dan DNS rebinding ke alamat internal juga tertolak. Proxy dari
+// environment tidak dipakai.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !Allowed(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrBlockedDestination, address)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("terlalu banyak redirect")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect ke %s", ErrBlockedDestination, req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
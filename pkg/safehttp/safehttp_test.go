@@ -0,0 +1,68 @@
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":              true,
+		"203.0.113.10":         true,
+		"2606:4700::1111":      true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"198.18.0.1":           false,
+		"255.255.255.255":      false,
+		"224.0.0.1":            false,
+		"::1":                  false,
+		"::":                   false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:169.254.169.1": false,
+		"64:ff9b::a00:1":       false,
+	}
+	for raw, want := range tests {
+		t.Run(raw, func(t *testing.T) {
+			if got := Allowed(netip.MustParseAddr(raw)); got != want {
+				t.Errorf("Allowed(%s) = %v, want %v", raw, got, want)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "http://8.8.8.8/hook"},
+		{url: "https://[2606:4700::1111]:8443/hook"},
+		{url: "http://127.0.0.1:8000/hook", wantErr: true},
+		{url: "http://localhost/hook", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://[::1]/hook", wantErr: true},
+		{url: "ftp://8.8.8.8/hook", wantErr: true},
+		{url: "/relatif", wantErr: true},
+		{url: "http://", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := CheckURL(context.Background(), tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrBlockedDestination) {
+				t.Errorf("err = %v, want %v", err, ErrBlockedDestination)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package shipping
+
+// FlatRate menagih tarif yang sama per kg untuk semua rute.
+type FlatRate struct {
+	code     string
+	perKg    int
+	estimasi string
+}
+
+func NewFlatRate(code string, perKg int, estimasi string) *FlatRate {
+	return &FlatRate{code: code, perKg: perKg, estimasi: estimasi}
+}
+
+func (c *FlatRate) Code() string {
+	return c.code
+}
+
+func (c *FlatRate) Quote(parcel Parcel) (*Quote, error) {
+	return &Quote{Ongkir: c.perKg * parcel.Kg(), Estimasi: c.estimasi}, nil
+}
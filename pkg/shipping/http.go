@@ -0,0 +1,56 @@
+package shipping
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// HTTPCourier meminta ongkir ke API kurir eksternal. Parcel dikirim
+// sebagai JSON lewat POST dan API membalas Quote dalam JSON. Status 404
+// atau 422 berarti rute tidak dilayani. Untuk pengujian, url bisa diarahkan
+// ke server stub yang membalas ongkir tetap.
+type HTTPCourier struct {
+	code string
+	url  string
+}
+
+func NewHTTPCourier(code, url string) *HTTPCourier {
+	return &HTTPCourier{code: code, url: url}
+}
+
+func (c *HTTPCourier) Code() string {
+	return c.code
+}
+
+func (c *HTTPCourier) Quote(parcel Parcel) (*Quote, error) {
+	body, err := json.Marshal(parcel)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghubungi kurir %s: %w", c.code, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity:
+		return nil, ErrNotServed
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return nil, fmt.Errorf("kurir %s membalas status %d", c.code, resp.StatusCode)
+	}
+
+	var quote Quote
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return nil, fmt.Errorf("balasan kurir %s tidak valid: %w", c.code, err)
+	}
+	if quote.Ongkir < 0 {
+		return nil, fmt.Errorf("balasan kurir %s tidak valid: ongkir negatif", c.code)
+	}
+	return &quote, nil
+}
@@ -0,0 +1,71 @@
+package shipping
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPCourierQuote(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    Quote
+		wantErr error
+		anyErr  bool
+	}{
+		{name: "ongkir tetap", status: http.StatusOK, body: `{"ongkir":12000,"estimasi":"1 hari"}`, want: Quote{Ongkir: 12000, Estimasi: "1 hari"}},
+		{name: "rute tidak ditemukan", status: http.StatusNotFound, wantErr: ErrNotServed},
+		{name: "rute tidak bisa diproses", status: http.StatusUnprocessableEntity, wantErr: ErrNotServed},
+		{name: "server kurir error", status: http.StatusBadGateway, anyErr: true},
+		{name: "ongkir negatif", status: http.StatusOK, body: `{"ongkir":-1}`, anyErr: true},
+		{name: "balasan bukan JSON", status: http.StatusOK, body: `ok`, anyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Parcel
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("parcel tidak terbaca: %v", err)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			parcel := Parcel{AsalKota: 1, TujuanKota: 2, BeratGram: 1500, VolumeCm3: 1000}
+			quote, err := NewHTTPCourier("kurir", server.URL).Quote(parcel)
+			if got != parcel {
+				t.Errorf("parcel terkirim = %+v, want %+v", got, parcel)
+			}
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil || errors.Is(err, ErrNotServed) {
+					t.Fatalf("err = %v, want error selain %v", err, ErrNotServed)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case *quote != tt.want:
+				t.Errorf("quote = %+v, want %+v", *quote, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPCourierUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	_, err := NewHTTPCourier("kurir", url).Quote(Parcel{BeratGram: 100})
+	if err == nil || errors.Is(err, ErrNotServed) {
+		t.Fatalf("err = %v, want error koneksi", err)
+	}
+}
@@ -0,0 +1,77 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrNotServed = errors.New("rute tidak dilayani kurir")
+
+// Parcel adalah satu paket yang dikirim dari satu toko. Lokasi memakai ID
+// kota yang sama dengan User.IDKota.
+type Parcel struct {
+	AsalKota   uint `json:"asal_kota"`
+	TujuanKota uint `json:"tujuan_kota"`
+	BeratGram  int  `json:"berat_gram"`
+	VolumeCm3  int  `json:"volume_cm3"`
+}
+
+// Kg adalah berat yang ditagihkan dalam kilogram: berat aktual atau berat
+// volumetrik (volume / 6000), mana yang lebih besar, dibulatkan ke atas
+// dan minimal 1 kg.
+func (p Parcel) Kg() int {
+	gram := max(p.BeratGram, p.VolumeCm3/6)
+	return max((gram+999)/1000, 1)
+}
+
+type Quote struct {
+	Ongkir   int    `json:"ongkir"`
+	Estimasi string `json:"estimasi"`
+}
+
+// Courier menghitung ongkir satu paket. Quote mengembalikan ErrNotServed
+// bila rute paket tidak dilayani.
+type Courier interface {
+	Code() string
+	Quote(parcel Parcel) (*Quote, error)
+}
+
+// NewFromEnv membuat daftar kurir yang aktif. Kurir "flat" selalu aktif,
+// kurir tabel dan HTTP hanya aktif bila konfigurasinya diisi.
+//
+//	SHIPPING_FLAT_RATE=10000
+//	SHIPPING_FLAT_ESTIMASI=2-4 hari
+//	SHIPPING_RATE_TABLE=./shipping_rates.json
+//	SHIPPING_HTTP_CODE=http
+//	SHIPPING_HTTP_URL=http://localhost:4000/quote
+func NewFromEnv() ([]Courier, error) {
+	perKg := 10000
+	if value := os.Getenv("SHIPPING_FLAT_RATE"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("SHIPPING_FLAT_RATE tidak valid: %q", value)
+		}
+		perKg = n
+	}
+	couriers := []Courier{NewFlatRate("flat", perKg, os.Getenv("SHIPPING_FLAT_ESTIMASI"))}
+
+	if path := os.Getenv("SHIPPING_RATE_TABLE"); path != "" {
+		rates, err := LoadRates(path)
+		if err != nil {
+			return nil, err
+		}
+		couriers = append(couriers, NewTableRate("table", rates))
+	}
+
+	if url := os.Getenv("SHIPPING_HTTP_URL"); url != "" {
+		code := strings.ToLower(os.Getenv("SHIPPING_HTTP_CODE"))
+		if code == "" {
+			code = "http"
+		}
+		couriers = append(couriers, NewHTTPCourier(code, url))
+	}
+	return couriers, nil
+}
@@ -0,0 +1,100 @@
+package shipping
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParcelKg(t *testing.T) {
+	tests := []struct {
+		name   string
+		parcel Parcel
+		want   int
+	}{
+		{"tanpa berat", Parcel{}, 1},
+		{"di bawah 1 kg", Parcel{BeratGram: 300}, 1},
+		{"tepat 1 kg", Parcel{BeratGram: 1000}, 1},
+		{"lebih 1 gram dibulatkan ke atas", Parcel{BeratGram: 1001}, 2},
+		{"berat aktual lebih besar", Parcel{BeratGram: 2500, VolumeCm3: 6000}, 3},
+		{"berat volumetrik lebih besar", Parcel{BeratGram: 500, VolumeCm3: 18000}, 3},
+		{"volumetrik dibulatkan ke atas", Parcel{VolumeCm3: 6006}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.parcel.Kg(); got != tt.want {
+				t.Errorf("Kg() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatRateQuote(t *testing.T) {
+	quote, err := NewFlatRate("flat", 10000, "2-4 hari").Quote(Parcel{AsalKota: 1, TujuanKota: 99, BeratGram: 1500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Ongkir != 20000 || quote.Estimasi != "2-4 hari" {
+		t.Errorf("quote = %+v, want ongkir 20000 estimasi 2-4 hari", quote)
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	ratesPath := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(ratesPath, []byte(`[{"asal_kota":1,"tujuan_kota":2,"per_kg":5000}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantCodes []string
+		wantErr   bool
+	}{
+		{name: "default hanya flat", wantCodes: []string{"flat"}},
+		{name: "flat rate diisi", env: map[string]string{"SHIPPING_FLAT_RATE": "8000"}, wantCodes: []string{"flat"}},
+		{name: "flat rate bukan angka", env: map[string]string{"SHIPPING_FLAT_RATE": "murah"}, wantErr: true},
+		{name: "flat rate nol", env: map[string]string{"SHIPPING_FLAT_RATE": "0"}, wantErr: true},
+		{name: "tabel tarif", env: map[string]string{"SHIPPING_RATE_TABLE": ratesPath}, wantCodes: []string{"flat", "table"}},
+		{name: "tabel tarif tidak ada", env: map[string]string{"SHIPPING_RATE_TABLE": filepath.Join(t.TempDir(), "x.json")}, wantErr: true},
+		{name: "kurir http tanpa kode", env: map[string]string{"SHIPPING_HTTP_URL": "http://kurir.test/quote"}, wantCodes: []string{"flat", "http"}},
+		{name: "kode kurir http dikecilkan", env: map[string]string{"SHIPPING_HTTP_URL": "http://kurir.test/quote", "SHIPPING_HTTP_CODE": "JNE"}, wantCodes: []string{"flat", "jne"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"SHIPPING_FLAT_RATE", "SHIPPING_FLAT_ESTIMASI", "SHIPPING_RATE_TABLE", "SHIPPING_HTTP_CODE", "SHIPPING_HTTP_URL"} {
+				t.Setenv(key, tt.env[key])
+			}
+			couriers, err := NewFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(couriers) != len(tt.wantCodes) {
+				t.Fatalf("jumlah kurir = %d, want %d", len(couriers), len(tt.wantCodes))
+			}
+			for i, courier := range couriers {
+				if courier.Code() != tt.wantCodes[i] {
+					t.Errorf("kurir[%d] = %q, want %q", i, courier.Code(), tt.wantCodes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewFromEnvFlatRate(t *testing.T) {
+	t.Setenv("SHIPPING_FLAT_RATE", "8000")
+	t.Setenv("SHIPPING_FLAT_ESTIMASI", "1 hari")
+	t.Setenv("SHIPPING_RATE_TABLE", "")
+	t.Setenv("SHIPPING_HTTP_URL", "")
+	couriers, err := NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote, err := couriers[0].Quote(Parcel{BeratGram: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Ongkir != 16000 || quote.Estimasi != "1 hari" {
+		t.Errorf("quote = %+v, want ongkir 16000 estimasi 1 hari", quote)
+	}
+}
@@ -0,0 +1,64 @@
+package shipping
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Rate adalah tarif per kg dari AsalKota ke TujuanKota.
+type Rate struct {
+	AsalKota   uint   `json:"asal_kota"`
+	TujuanKota uint   `json:"tujuan_kota"`
+	PerKg      int    `json:"per_kg"`
+	Estimasi   string `json:"estimasi"`
+}
+
+type rute struct {
+	asal, tujuan uint
+}
+
+// TableRate menagih tarif sesuai tabel rute. Rute yang tidak ada di tabel
+// tidak dilayani.
+type TableRate struct {
+	code  string
+	rates map[rute]Rate
+}
+
+func NewTableRate(code string, rates []Rate) *TableRate {
+	table := &TableRate{code: code, rates: make(map[rute]Rate, len(rates))}
+	for _, rate := range rates {
+		table.rates[rute{rate.AsalKota, rate.TujuanKota}] = rate
+	}
+	return table
+}
+
+// LoadRates membaca tabel tarif berformat JSON array Rate dari path.
+func LoadRates(path string) ([]Rate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca tabel ongkir: %w", err)
+	}
+	var rates []Rate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("tabel ongkir %s tidak valid: %w", path, err)
+	}
+	for _, rate := range rates {
+		if rate.PerKg <= 0 {
+			return nil, fmt.Errorf("tabel ongkir %s tidak valid: per_kg rute %d-%d harus positif", path, rate.AsalKota, rate.TujuanKota)
+		}
+	}
+	return rates, nil
+}
+
+func (c *TableRate) Code() string {
+	return c.code
+}
+
+func (c *TableRate) Quote(parcel Parcel) (*Quote, error) {
+	rate, ok := c.rates[rute{parcel.AsalKota, parcel.TujuanKota}]
+	if !ok {
+		return nil, ErrNotServed
+	}
+	return &Quote{Ongkir: rate.PerKg * parcel.Kg(), Estimasi: rate.Estimasi}, nil
+}
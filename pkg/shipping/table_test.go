@@ -0,0 +1,66 @@
+package shipping
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTableRateQuote(t *testing.T) {
+	table := NewTableRate("table", []Rate{
+		{AsalKota: 1, TujuanKota: 2, PerKg: 5000, Estimasi: "1-2 hari"},
+		{AsalKota: 2, TujuanKota: 1, PerKg: 7000, Estimasi: "2-3 hari"},
+	})
+	tests := []struct {
+		name    string
+		parcel  Parcel
+		want    Quote
+		wantErr error
+	}{
+		{name: "rute ada", parcel: Parcel{AsalKota: 1, TujuanKota: 2, BeratGram: 2100}, want: Quote{Ongkir: 15000, Estimasi: "1-2 hari"}},
+		{name: "arah sebaliknya punya tarif sendiri", parcel: Parcel{AsalKota: 2, TujuanKota: 1, BeratGram: 100}, want: Quote{Ongkir: 7000, Estimasi: "2-3 hari"}},
+		{name: "rute tidak ada", parcel: Parcel{AsalKota: 1, TujuanKota: 3, BeratGram: 100}, wantErr: ErrNotServed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := table.Quote(tt.parcel)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && *quote != tt.want {
+				t.Errorf("quote = %+v, want %+v", *quote, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{name: "valid", content: `[{"asal_kota":1,"tujuan_kota":2,"per_kg":5000,"estimasi":"1 hari"}]`, want: 1},
+		{name: "kosong", content: `[]`, want: 0},
+		{name: "bukan JSON", content: `asal,tujuan`, wantErr: true},
+		{name: "per_kg nol", content: `[{"asal_kota":1,"tujuan_kota":2,"per_kg":0}]`, wantErr: true},
+		{name: "per_kg negatif", content: `[{"asal_kota":1,"tujuan_kota":2,"per_kg":-1}]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rates.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			rates, err := LoadRates(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(rates) != tt.want {
+				t.Errorf("jumlah tarif = %d, want %d", len(rates), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissingSecret    = errors.New("FILE_SIGNING_SECRET belum di-set")
+	ErrInvalidSignature = errors.New("signature tidak valid")
+	ErrExpired          = errors.New("url sudah kedaluwarsa")
+)
+
+// PrivatePrefix adalah prefix key untuk file yang hanya bisa diakses lewat
+// URL bertanda tangan, misalnya invoice PDF atau foto bukti retur.
+const PrivatePrefix = "private/"
+
+func IsPrivate(key string) bool {
+	return strings.HasPrefix(key, PrivatePrefix)
+}
+
+func secret() []byte {
+	return []byte(os.Getenv("FILE_SIGNING_SECRET"))
+}
+
+// Sign menghitung HMAC-SHA256 atas key dan waktu kedaluwarsa.
+func Sign(key string, expiresAt int64) (string, error) {
+	s := secret()
+	if len(s) == 0 {
+		return "", ErrMissingSecret
+	}
+	mac := hmac.New(sha256.New, s)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expiresAt, 10)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// URL membuat URL file yang berlaku selama ttl. baseURL adalah prefix route
+// file server, misalnya "/files" atau "https://api.example.com/files".
+func URL(baseURL, key string, ttl time.Duration) (string, error) {
+	expiresAt := time.Now().Add(ttl).Unix()
+	signature, err := Sign(key, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expiresAt, 10))
+	q.Set("signature", signature)
+	return strings.TrimSuffix(baseURL, "/") + "/" + key + "?" + q.Encode(), nil
+}
+
+// Verify memeriksa signature dan mengembalikan waktu kedaluwarsanya.
+func Verify(key, expires, signature string, now time.Time) (time.Time, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidSignature
+	}
+	expected, err := Sign(key, expiresAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return time.Time{}, ErrInvalidSignature
+	}
+	exp := time.Unix(expiresAt, 0)
+	if !now.Before(exp) {
+		return time.Time{}, ErrExpired
+	}
+	return exp, nil
+}
@@ -0,0 +1,94 @@
+package signedurl
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	t.Setenv("FILE_SIGNING_SECRET", "rahasia")
+	now := time.Unix(1_700_000_000, 0)
+	expiresAt := now.Add(time.Hour).Unix()
+	signature, err := Sign("private/invoice.pdf", expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires := strconv.FormatInt(expiresAt, 10)
+
+	tests := []struct {
+		name      string
+		key       string
+		expires   string
+		signature string
+		now       time.Time
+		wantErr   error
+	}{
+		{name: "valid", key: "private/invoice.pdf", expires: expires, signature: signature, now: now},
+		{name: "key lain", key: "private/lain.pdf", expires: expires, signature: signature, now: now, wantErr: ErrInvalidSignature},
+		{name: "expires diubah", key: "private/invoice.pdf", expires: strconv.FormatInt(expiresAt+60, 10), signature: signature, now: now, wantErr: ErrInvalidSignature},
+		{name: "expires bukan angka", key: "private/invoice.pdf", expires: "besok", signature: signature, now: now, wantErr: ErrInvalidSignature},
+		{name: "signature kosong", key: "private/invoice.pdf", expires: expires, now: now, wantErr: ErrInvalidSignature},
+		{name: "kedaluwarsa", key: "private/invoice.pdf", expires: expires, signature: signature, now: now.Add(time.Hour), wantErr: ErrExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp, err := Verify(tt.key, tt.expires, tt.signature, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && exp.Unix() != expiresAt {
+				t.Errorf("expires = %d, want %d", exp.Unix(), expiresAt)
+			}
+		})
+	}
+}
+
+func TestSignWithoutSecret(t *testing.T) {
+	t.Setenv("FILE_SIGNING_SECRET", "")
+	if _, err := Sign("private/a.pdf", 1); !errors.Is(err, ErrMissingSecret) {
+		t.Fatalf("err = %v, want %v", err, ErrMissingSecret)
+	}
+	if _, err := Verify("private/a.pdf", "1", "x", time.Unix(0, 0)); !errors.Is(err, ErrMissingSecret) {
+		t.Fatalf("err = %v, want %v", err, ErrMissingSecret)
+	}
+}
+
+func TestURL(t *testing.T) {
+	t.Setenv("FILE_SIGNING_SECRET", "rahasia")
+	for _, base := range []string{"/files", "/files/", "https://api.example.com/files"} {
+		t.Run(base, func(t *testing.T) {
+			raw, err := URL(base, "private/a.pdf", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := url.Parse(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(u.Path, "/files/private/a.pdf") || strings.Contains(u.Path, "//") {
+				t.Errorf("path = %s", u.Path)
+			}
+			q := u.Query()
+			if _, err := Verify("private/a.pdf", q.Get("expires"), q.Get("signature"), time.Now()); err != nil {
+				t.Errorf("Verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestIsPrivate(t *testing.T) {
+	tests := map[string]bool{
+		"private/a.pdf":  true,
+		"ab/cd/hash.jpg": false,
+		"privatefile":    false,
+	}
+	for key, want := range tests {
+		if got := IsPrivate(key); got != want {
+			t.Errorf("IsPrivate(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("object tidak ditemukan")
+
+// Storage adalah tempat penyimpanan file upload. Key berupa path relatif
+// dengan pemisah "/", misalnya "1700000000-ab12cd.jpg".
+type Storage interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	URL(key string) string
+	// List memanggil fn untuk setiap object beserta waktu terakhir
+	// diubah, dipakai garbage collector untuk mencari file yang yatim.
+	List(fn func(key string, modTime time.Time) error) error
+}
+
+// NewFromEnv membuat Storage sesuai STORAGE_DRIVER ("local" atau "s3").
+//
+//	STORAGE_DRIVER=local
+//	STORAGE_LOCAL_ROOT=./public/uploads
+//	STORAGE_PUBLIC_URL=/files
+//
+//	STORAGE_DRIVER=s3
+//	S3_ENDPOINT=http://localhost:9000
+//	S3_REGION=us-east-1
+//	S3_BUCKET=uploads
+//	S3_ACCESS_KEY=minioadmin
+//	S3_SECRET_KEY=minioadmin
+//	STORAGE_PUBLIC_URL=http://localhost:9000/uploads
+func NewFromEnv() (Storage, error) {
+	publicURL := os.Getenv("STORAGE_PUBLIC_URL")
+
+	switch driver := strings.ToLower(os.Getenv("STORAGE_DRIVER")); driver {
+	case "", "local":
+		root := os.Getenv("STORAGE_LOCAL_ROOT")
+		if root == "" {
+			root = "./public/uploads"
+		}
+		if publicURL == "" {
+			publicURL = "/files"
+		}
+		return NewLocalStorage(root, publicURL)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: publicURL,
+		})
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER tidak dikenal: %q", driver)
+	}
+}
+
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("key tidak valid: %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("key tidak valid: %q", key)
+		}
+	}
+	return nil
+}
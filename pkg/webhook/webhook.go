@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"test-rakamin/pkg/safehttp"
+	"time"
+)
+
+// client hanya menghubungi alamat publik karena URL webhook diisi seller.
+var client = safehttp.NewClient(10 * time.Second)
+
+type Event struct {
+	Event  string      `json:"event"`
+	Data   interface{} `json:"data"`
+	SentAt time.Time   `json:"sent_at"`
+}
+
+// NewSecret membuat secret acak untuk menandatangani webhook sebuah toko.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Send mengirim event sebagai JSON ke targetURL. Body ditandatangani
+// HMAC-SHA256 dengan secret milik penerima di header X-Webhook-Signature
+// supaya penerima bisa memastikan asalnya.
+func Send(targetURL, secret, event string, data interface{}) error {
+	body, err := json.Marshal(Event{Event: event, Data: data, SentAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event)
+	if secret != "" {
+		req.Header.Set("X-Webhook-Signature", Sign(secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with status %d", targetURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign mengembalikan tanda tangan body dengan format "sha256=<HMAC hex>",
+// sama seperti header X-Webhook-Signature yang dikirim Send.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify memeriksa signature webhook masuk yang dibuat dengan Sign.
+// Secret kosong selalu ditolak.
+func Verify(secret string, body []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"event":"low_stock"}`)
+	signature := Sign("rahasia", body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: "rahasia", body: body, signature: signature, want: true},
+		{name: "secret lain", secret: "bukan", body: body, signature: signature},
+		{name: "body diubah", secret: "rahasia", body: []byte(`{"event":"out_of_stock"}`), signature: signature},
+		{name: "tanpa prefix", secret: "rahasia", body: body, signature: signature[len("sha256="):]},
+		{name: "signature kosong", secret: "rahasia", body: body},
+		{name: "secret kosong", body: body, signature: Sign("", body)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 64 || a == b {
+		t.Errorf("NewSecret = %q, %q", a, b)
+	}
+}
+
+func TestSendBlocksInternalDestination(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	if err := Send(srv.URL, "rahasia", "low_stock", nil); err == nil {
+		t.Fatal("Send ke loopback harus ditolak")
+	}
+	if called {
+		t.Error("server loopback tetap dihubungi")
+	}
+}
+
+func TestSendSignsBody(t *testing.T) {
+	var gotBody []byte
+	var gotHeader http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeader = r.Header
+	}))
+	defer srv.Close()
+
+	// Server uji ada di loopback, jadi client bawaan dipakai sementara.
+	saved := client
+	client = srv.Client()
+	defer func() { client = saved }()
+
+	if err := Send(srv.URL, "rahasia", "low_stock", map[string]int{"stok": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !Verify("rahasia", gotBody, gotHeader.Get("X-Webhook-Signature")) {
+		t.Errorf("signature %q tidak valid", gotHeader.Get("X-Webhook-Signature"))
+	}
+	if gotHeader.Get("X-Webhook-Event") != "low_stock" {
+		t.Errorf("X-Webhook-Event = %q", gotHeader.Get("X-Webhook-Event"))
+	}
+	var event Event
+	if err := json.Unmarshal(gotBody, &event); err != nil || event.Event != "low_stock" {
+		t.Errorf("body = %s, err %v", gotBody, err)
+	}
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// Every menjalankan fn setiap interval di goroutine terpisah. Error dari
+// fn hanya dicatat ke log supaya worker tetap berjalan. Panggil fungsi
+// yang dikembalikan untuk menghentikan worker.
+func Every(name string, interval time.Duration, fn func() error) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := fn(); err != nil {
+					log.Printf("%s: %v", name, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// DurationFromEnv membaca durasi seperti "30m" atau "1h" dari env key.
+// Nilai kosong atau tidak valid memakai def.
+func DurationFromEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("%s tidak valid (%q), memakai %s", key, v, def)
+		return def
+	}
+	return d
+}
@@ -1,45 +1,153 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
+	"net/http"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"test-rakamin/pkg/imageproc"
+	"test-rakamin/pkg/safehttp"
+	"test-rakamin/pkg/storage"
 )
 
-// SaveUploadedFile menyimpan file yang diunggah ke direktori public/uploads
-// dan mengembalikan nama file baru yang dibuat.
-func SaveUploadedFile(file *multipart.FileHeader) (string, error) {
-	// Buat direktori "public/uploads" jika belum ada
-	uploadDir := "./public/uploads"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		return "", fmt.Errorf("gagal membuat direktori upload: %w", err)
-	}
+// ErrInvalidUpload membungkus semua error validasi upload (tipe, ukuran,
+// dimensi) sehingga handler bisa membalas 400 alih-alih 500.
+var ErrInvalidUpload = errors.New("file upload tidak valid")
+
+var uploadConfig = sync.OnceValues(imageproc.LoadConfigFromEnv)
 
-	// Buat nama file unik dengan timestamp untuk menghindari duplikasi
-	filename := fmt.Sprintf("%d-%s", time.Now().Unix(), filepath.Base(file.Filename))
-	filePath := filepath.Join(uploadDir, filename)
+// ReadUploadedFile membaca seluruh isi file yang diunggah dengan batas
+// ukuran dari konfigurasi upload.
+func ReadUploadedFile(file *multipart.FileHeader) ([]byte, error) {
+	cfg, err := uploadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("konfigurasi upload tidak valid: %w", err)
+	}
+	if file.Size > cfg.MaxBytes {
+		return nil, fmt.Errorf("%w: %v (maksimal %d byte)", ErrInvalidUpload, imageproc.ErrTooLarge, cfg.MaxBytes)
+	}
 
-	// Buka file yang diunggah
 	src, err := file.Open()
 	if err != nil {
-		return "", fmt.Errorf("gagal membuka file yang diunggah: %w", err)
+		return nil, fmt.Errorf("gagal membuka file yang diunggah: %w", err)
 	}
 	defer src.Close()
 
-	// Buat file baru di direktori tujuan
-	dst, err := os.Create(filePath)
+	data, err := io.ReadAll(io.LimitReader(src, cfg.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file yang diunggah: %w", err)
+	}
+	if int64(len(data)) > cfg.MaxBytes {
+		return nil, fmt.Errorf("%w: %v (maksimal %d byte)", ErrInvalidUpload, imageproc.ErrTooLarge, cfg.MaxBytes)
+	}
+	return data, nil
+}
+
+// downloadClient hanya menghubungi alamat publik, termasuk saat mengikuti
+// redirect, karena URL foto berasal dari file import seller.
+var downloadClient = safehttp.NewClient(15 * time.Second)
+
+// DownloadImage mengunduh gambar dari URL http/https publik dengan batas
+// ukuran yang sama seperti upload biasa.
+func DownloadImage(rawURL string) ([]byte, error) {
+	cfg, err := uploadConfig()
 	if err != nil {
-		return "", fmt.Errorf("gagal membuat file tujuan: %w", err)
+		return nil, fmt.Errorf("konfigurasi upload tidak valid: %w", err)
+	}
+	if err := safehttp.CheckURL(context.Background(), rawURL); err != nil {
+		return nil, fmt.Errorf("%w: URL foto %s: %v", ErrInvalidUpload, rawURL, err)
 	}
-	defer dst.Close()
 
-	// Salin isi file
-	if _, err := io.Copy(dst, src); err != nil {
-		return "", fmt.Errorf("gagal menyalin file: %w", err)
+	resp, err := downloadClient.Get(rawURL)
+	if errors.Is(err, safehttp.ErrBlockedDestination) {
+		return nil, fmt.Errorf("%w: URL foto %s: %v", ErrInvalidUpload, rawURL, safehttp.ErrBlockedDestination)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: gagal mengunduh %s: %v", ErrInvalidUpload, rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: gagal mengunduh %s: status %d", ErrInvalidUpload, rawURL, resp.StatusCode)
 	}
 
-	return filename, nil
+	data, err := io.ReadAll(io.LimitReader(resp.Body, cfg.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: gagal mengunduh %s: %v", ErrInvalidUpload, rawURL, err)
+	}
+	if int64(len(data)) > cfg.MaxBytes {
+		return nil, fmt.Errorf("%w: %v (maksimal %d byte)", ErrInvalidUpload, imageproc.ErrTooLarge, cfg.MaxBytes)
+	}
+	return data, nil
+}
+
+// SaveImage memvalidasi dan memproses gambar, lalu menyimpan gambar utama
+// beserta thumbnail-nya ke store dengan key baseKey + ekstensi hasil encode.
+func SaveImage(store storage.Storage, baseKey string, data []byte) (string, error) {
+	cfg, err := uploadConfig()
+	if err != nil {
+		return "", fmt.Errorf("konfigurasi upload tidak valid: %w", err)
+	}
+
+	result, err := imageproc.Process(bytes.NewReader(data), cfg)
+	if err != nil {
+		if errors.Is(err, imageproc.ErrUnsupportedType) || errors.Is(err, imageproc.ErrTooLarge) || errors.Is(err, imageproc.ErrTooManyPixels) {
+			return "", fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+		}
+		return "", err
+	}
+
+	key := baseKey + result.Ext
+	if err := store.Put(key, bytes.NewReader(result.Original), int64(len(result.Original)), result.ContentType); err != nil {
+		return "", fmt.Errorf("gagal menyimpan file: %w", err)
+	}
+	for name, data := range result.Variants {
+		if err := store.Put(variantName(key, name), bytes.NewReader(data), int64(len(data)), result.ContentType); err != nil {
+			return "", fmt.Errorf("gagal menyimpan thumbnail %s: %w", name, err)
+		}
+	}
+
+	return key, nil
+}
+
+// UploadVariants mengembalikan URL untuk setiap ukuran gambar yang dibuat
+// SaveImage, termasuk "original".
+func UploadVariants(store storage.Storage, filename string) map[string]string {
+	if filename == "" {
+		return nil
+	}
+	variants := map[string]string{"original": store.URL(filename)}
+	cfg, err := uploadConfig()
+	if err != nil {
+		return variants
+	}
+	for _, t := range cfg.Thumbnails {
+		variants[t.Name] = store.URL(variantName(filename, t.Name))
+	}
+	return variants
+}
+
+// UploadVariantKeys mengembalikan key gambar utama dan semua thumbnail-nya.
+func UploadVariantKeys(key string) []string {
+	keys := []string{key}
+	cfg, err := uploadConfig()
+	if err != nil {
+		return keys
+	}
+	for _, t := range cfg.Thumbnails {
+		keys = append(keys, variantName(key, t.Name))
+	}
+	return keys
+}
+
+func variantName(filename, variant string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "_" + variant + ext
 }
@@ -43,3 +43,34 @@ func JWTMiddleware() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// OptionalJWTMiddleware mengisi user_id bila request membawa token yang
+// valid, tetapi tetap meneruskan request tanpa token. Dipakai oleh endpoint
+// publik yang responnya bisa berbeda untuk user yang login.
+func OptionalJWTMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authHeader := c.Get("Authorization")
+		if authHeader == "" {
+			return c.Next()
+		}
+
+		tokenString := authHeader
+
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+			tokenString = authHeader[7:]
+		}
+
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("JWT_SECRET")), nil
+		})
+		if err != nil || !token.Valid {
+			return c.Next()
+		}
+
+		if userIDFloat, ok := claims["id"].(float64); ok {
+			c.Locals("user_id", uint(userIDFloat))
+		}
+		return c.Next()
+	}
+}